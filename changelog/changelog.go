@@ -10,6 +10,7 @@ package changelog
 import (
 	"os"
 
+	"github.com/Masterminds/semver"
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
@@ -23,6 +24,8 @@ type Controller interface {
 	HasReleases() bool
 	FirstRelease() *Release
 	LatestRelease() *Release
+	LatestVersionRelease() *Release
+	ReleaseByVersion(string) *Release
 }
 
 // Changelog represents the changelog itself.
@@ -182,3 +185,31 @@ func (c *Changelog) LatestRelease() *Release {
 	}
 	return nil
 }
+
+// LatestVersionRelease returns the latest Release which has a version. Unlike
+// LatestRelease, it skips the "Unreleased" one.
+func (c *Changelog) LatestVersionRelease() *Release {
+	for i := range c.Releases {
+		if c.Releases[i].Version != nil {
+			return &c.Releases[i]
+		}
+	}
+	return nil
+}
+
+// ReleaseByVersion returns a Release with the provided version or nil if it
+// doesn't exist.
+func (c *Changelog) ReleaseByVersion(version string) *Release {
+	ver, err := semver.NewVersion(version)
+	if err != nil {
+		return nil
+	}
+
+	for i := range c.Releases {
+		if c.Releases[i].Version != nil && c.Releases[i].Version.Equal(ver) {
+			return &c.Releases[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testChangelog = `# Changelog
+
+## [Unreleased][]
+
+### Added
+
+- Upcoming feature
+
+## [1.10.0][] - 2021-03-01
+
+### Added
+
+- First addition
+- Second addition
+
+### Fixed
+
+- Some bug
+
+## [1.1.0-beta][] - 2021-02-01
+
+### Changed
+
+- Beta change
+
+## [1.1.0][] - 2021-01-15
+
+### Removed
+
+- Old option
+
+## [1.0.0][] - 2021-01-01
+
+Initial release.
+
+[unreleased]: https://example.com/compare/v1.10.0...HEAD
+[1.10.0]: https://example.com/compare/v1.1.0...v1.10.0
+[1.1.0-beta]: https://example.com/compare/v1.1.0...v1.1.0-beta
+[1.1.0]: https://example.com/compare/v1.0.0...v1.1.0
+[1.0.0]: https://example.com/releases/tag/v1.0.0
+`
+
+// loadChangelog loads a changelog from a CHANGELOG.md with the provided
+// content.
+func loadChangelog(t *testing.T, content string) *Changelog {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+
+	c := New()
+	assert.Nil(t, c.Load(path))
+	return c
+}
+
+func TestChangelog_Load(t *testing.T) {
+	c := loadChangelog(t, testChangelog)
+
+	titles := make([]string, len(c.Releases))
+	for i, r := range c.Releases {
+		titles[i] = r.Title
+	}
+
+	assert.Equal(t, []string{
+		"Unreleased",
+		"1.10.0 - 2021-03-01",
+		"1.1.0-beta - 2021-02-01",
+		"1.1.0 - 2021-01-15",
+		"1.0.0 - 2021-01-01",
+	}, titles)
+
+	assert.Equal(t, "Unreleased", c.LatestRelease().Title)
+	assert.Equal(t, "1.0.0 - 2021-01-01", c.FirstRelease().Title)
+	assert.Equal(t, "Initial release.", c.FirstRelease().Text)
+	assert.Equal(t, "https://example.com/compare/v1.1.0...v1.10.0", c.Releases[1].Link)
+	assert.Equal(t, "2021-03-01", c.Releases[1].DateString())
+}
+
+func TestChangelog_Load_Error(t *testing.T) {
+	assert.NotNil(t, New().Load(filepath.Join(t.TempDir(), "CHANGELOG.md")))
+}
+
+func TestChangelog_LatestVersionRelease(t *testing.T) {
+	c := loadChangelog(t, testChangelog)
+	assert.Nil(t, c.Releases[0].Version)
+
+	r := c.LatestVersionRelease()
+	if assert.NotNil(t, r, "Unreleased should be skipped") {
+		assert.Equal(t, "1.10.0", r.Version.String())
+	}
+
+	c = loadChangelog(t, "# Changelog\n\n## [Unreleased][]\n\n### Added\n\n- Upcoming feature\n")
+	assert.Nil(t, c.LatestVersionRelease())
+
+	assert.Nil(t, New().LatestVersionRelease())
+	assert.Nil(t, New().LatestRelease())
+	assert.Nil(t, New().FirstRelease())
+}
+
+func TestChangelog_ReleaseByVersion(t *testing.T) {
+	c := loadChangelog(t, testChangelog)
+
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"1.10.0", "1.10.0 - 2021-03-01"},
+		{"v1.10.0", "1.10.0 - 2021-03-01"},
+		{"1.1.0", "1.1.0 - 2021-01-15"},
+		{"1.1.0-beta", "1.1.0-beta - 2021-02-01"},
+		{"1.0.0", "1.0.0 - 2021-01-01"},
+		{"1.1.1", ""},
+		{"1.1.0-rc.1", ""},
+		{"Unreleased", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		r := c.ReleaseByVersion(tt.version)
+		if len(tt.expected) == 0 {
+			assert.Nilf(t, r, "Version %q", tt.version)
+			continue
+		}
+		if assert.NotNilf(t, r, "Version %q", tt.version) {
+			assert.Equalf(t, tt.expected, r.Title, "Version %q", tt.version)
+		}
+	}
+}
+
+func TestRelease_PlainText(t *testing.T) {
+	c := loadChangelog(t, testChangelog)
+
+	assert.Equal(t, "Added:\n- First addition\n- Second addition\n\nFixed:\n- Some bug", c.Releases[1].PlainText())
+	assert.Equal(t, "Initial release.", c.FirstRelease().PlainText())
+
+	r := NewRelease()
+	r.Text = "Ignored when there are changes."
+	r.AddSecurity("Sixth")
+	r.AddFixed("Fifth")
+	r.AddRemoved("Fourth")
+	r.AddDeprecated("Third")
+	r.AddChanged("Second")
+	r.AddAdded("First")
+	r.AddAdded("First again")
+
+	assert.Equal(t, 7, r.CountChanges())
+	assert.Equal(t, `Added:
+- First
+- First again
+
+Changed:
+- Second
+
+Deprecated:
+- Third
+
+Removed:
+- Fourth
+
+Fixed:
+- Fifth
+
+Security:
+- Sixth`, r.PlainText())
+
+	assert.Equal(t, "", NewRelease().PlainText())
+}
@@ -2,7 +2,9 @@ package changelog
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/semver"
@@ -30,6 +32,7 @@ type ReleaseController interface {
 	HasChanges() bool
 	HasText() bool
 	DateString() string
+	PlainText() string
 }
 
 // Release represents a single CHANGELOG.md release.
@@ -186,6 +189,40 @@ func (r *Release) DateString() string {
 	return r.Date.Format("2006-01-02")
 }
 
+// PlainText returns a plain text representation of release changes grouped by
+// their types. If a release has no changes, its text is returned instead.
+func (r *Release) PlainText() string {
+	if !r.HasChanges() {
+		return r.Text
+	}
+
+	var groups []string
+
+	for _, group := range []struct {
+		name    string
+		changes []ReleaseChange
+	}{
+		{"Added", r.Added},
+		{"Changed", r.Changed},
+		{"Deprecated", r.Deprecated},
+		{"Removed", r.Removed},
+		{"Fixed", r.Fixed},
+		{"Security", r.Security},
+	} {
+		if len(group.changes) == 0 {
+			continue
+		}
+
+		lines := []string{fmt.Sprintf("%s:", group.name)}
+		for _, change := range group.changes {
+			lines = append(lines, fmt.Sprintf("- %s", change.Value))
+		}
+		groups = append(groups, strings.Join(lines, "\n"))
+	}
+
+	return strings.Join(groups, "\n\n")
+}
+
 // ReleaseChange represents a single release change. Created to be extended in
 // the future to hold values in different formats like Plain Text, Markdown and
 // Steam Workshop.
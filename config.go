@@ -5,18 +5,50 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/dstmodders/mod-cli/tools"
+	"github.com/dstmodders/mod-cli/workshop"
 	"gopkg.in/yaml.v2"
 )
 
 type Config struct {
+	Docker   ConfigDocker
 	Format   ConfigFormat
+	Jobs     int
 	Lint     ConfigLint
+	Test     ConfigTest
+	Tools    []ConfigCustomTool
+	Versions map[string]string
 	Workshop ConfigWorkshop
 	file     *os.File
 	yaml     ConfigYAML
 }
 
+type ConfigDocker struct {
+	ConfigDockerSettings
+	Bundle      string
+	Container   tools.ContainerMode
+	IdleTimeout time.Duration
+	Rootless    bool
+	Runtime     tools.Runtime
+	rootlessSet bool
+	runtimeSet  bool
+}
+
+type ConfigDockerSettings struct {
+	Args    []string
+	Env     map[string]string
+	Image   string
+	Mounts  []string
+	Network string
+	User    string
+}
+
 type ConfigFormat struct {
 	Prettier ConfigTool
 	StyLua   ConfigTool
@@ -26,15 +58,108 @@ type ConfigLint struct {
 	Luacheck ConfigTool
 }
 
+type ConfigTest struct {
+	Busted ConfigTool
+}
+
 type ConfigTool struct {
-	Docker  bool
-	Enabled bool
-	Fix     bool
-	Ignore  []string
+	Docker         bool
+	DockerSettings ConfigDockerSettings
+	Enabled        bool
+	Fix            bool
+	Ignore         []string
+}
+
+type ConfigCustomTool struct {
+	ConfigTool
+	Args         []string
+	Capabilities tools.Capability
+	Cmd          string
+	Ext          []string
+	FixArgs      []string
+	ID           string
+	Image        string
+	Name         string
+	Output       string
+	VersionArgs  []string
 }
 
 type ConfigWorkshop struct {
-	Ignore []string
+	Assets       ConfigWorkshopAssets
+	Compression  int
+	Format       string
+	Ignore       []string
+	Include      []string
+	Install      ConfigWorkshopInstall
+	Jobs         int
+	MaxSize      int64
+	Minify       ConfigWorkshopMinify
+	Out          string
+	Publish      ConfigWorkshopPublish
+	Reproducible bool
+	Transforms   []ConfigWorkshopTransform
+	Validate     bool
+}
+
+type ConfigWorkshopAssets struct {
+	Cache          string
+	Dest           string
+	Docker         bool
+	DockerSettings ConfigDockerSettings
+	Src            string
+}
+
+type ConfigWorkshopInstall struct {
+	Folder  string
+	Symlink bool
+	Target  string
+}
+
+type ConfigWorkshopMinify struct {
+	Enabled    bool
+	KeepLines  bool
+	Whitespace bool
+}
+
+type ConfigWorkshopTransform struct {
+	Files []string
+	Vars  map[string]string
+}
+
+type ConfigWorkshopPublish struct {
+	Changelog  string
+	ID         string
+	Preview    string
+	SteamCMD   string
+	Username   string
+	Visibility int
+}
+
+var sizeRegex = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([KMG]i?B|B)?$`)
+
+var sizeUnits = map[string]float64{
+	"":    1,
+	"B":   1,
+	"KB":  1 << 10,
+	"KiB": 1 << 10,
+	"MB":  1 << 20,
+	"MiB": 1 << 20,
+	"GB":  1 << 30,
+	"GiB": 1 << 30,
+}
+
+func parseSize(str string) (int64, error) {
+	match := sizeRegex.FindStringSubmatch(strings.TrimSpace(str))
+	if len(match) != 3 {
+		return 0, fmt.Errorf("invalid size: %s", str)
+	}
+
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(value * sizeUnits[match[2]]), nil
 }
 
 func NewConfig() *Config {
@@ -46,6 +171,9 @@ func NewConfig() *Config {
 	}
 
 	return &Config{
+		Docker: ConfigDocker{
+			IdleTimeout: tools.DefaultIdleTimeout,
+		},
 		Format: ConfigFormat{
 			Prettier: tool,
 			StyLua:   tool,
@@ -55,7 +183,25 @@ func NewConfig() *Config {
 				Enabled: true,
 			},
 		},
+		Test: ConfigTest{
+			Busted: ConfigTool{
+				Enabled: true,
+			},
+		},
 		Workshop: ConfigWorkshop{
+			Assets: ConfigWorkshopAssets{
+				Cache: ".assets",
+			},
+			Compression: -1,
+			Format:      "dir",
+			Minify: ConfigWorkshopMinify{
+				KeepLines: true,
+			},
+			Publish: ConfigWorkshopPublish{
+				Changelog: "CHANGELOG.md",
+				SteamCMD:  "steamcmd",
+			},
+			Validate: true,
 			Ignore: []string{
 				".*",
 				"CHANGELOG.md",
@@ -106,6 +252,64 @@ func (c *Config) toBool(name string, value interface{}, dest *bool) error {
 	}
 }
 
+func (c *Config) toInt(name string, value interface{}, dest *int) error {
+	expected := "int"
+	switch val := value.(type) {
+	case int:
+		*dest = val
+		return nil
+	default:
+		return c.errorExpected(name, expected, value)
+	}
+}
+
+func (c *Config) toString(name string, value interface{}, dest *string) error {
+	switch val := value.(type) {
+	case string:
+		*dest = val
+		return nil
+	case int:
+		*dest = strconv.Itoa(val)
+		return nil
+	default:
+		return c.errorExpected(name, "string", value)
+	}
+}
+
+func (c *Config) toSize(name string, value interface{}, dest *int64) error {
+	switch val := value.(type) {
+	case int:
+		*dest = int64(val)
+		return nil
+	case string:
+		size, err := parseSize(val)
+		if err != nil {
+			return c.errorValue(name, err.Error())
+		}
+		*dest = size
+		return nil
+	default:
+		return c.errorExpected(name, "int or string", value)
+	}
+}
+
+func (c *Config) toDuration(name string, value interface{}, dest *time.Duration) error {
+	switch val := value.(type) {
+	case int:
+		*dest = time.Duration(val) * time.Second
+		return nil
+	case string:
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return c.errorValue(name, err.Error())
+		}
+		*dest = d
+		return nil
+	default:
+		return c.errorExpected(name, "int or string", value)
+	}
+}
+
 func (c *Config) toSequence(name string, value interface{}, dest *[]string) error {
 	switch val := value.(type) {
 	case []interface{}:
@@ -128,10 +332,8 @@ func (c *Config) parseYAMLTool(name string, value interface{}, dest *ConfigTool)
 	case map[interface{}]interface{}:
 		dest.Enabled = true
 
-		if val["docker"] != nil {
-			if err := c.toBool(name, val["docker"], &dest.Docker); err != nil {
-				return err
-			}
+		if err := c.parseYAMLToolDocker(name+".docker", val["docker"], &dest.Docker, &dest.DockerSettings); err != nil {
+			return err
 		}
 
 		if val["fix"] != nil {
@@ -159,6 +361,167 @@ func (c *Config) parseYAMLTool(name string, value interface{}, dest *ConfigTool)
 	}
 }
 
+// parseYAMLToolDocker parses either a bool setting whether a tool is always run
+// through Docker or a mapping with the same in "enabled" along with the tool
+// Docker settings.
+func (c *Config) parseYAMLToolDocker(name string, value interface{}, enabled *bool, dest *ConfigDockerSettings) error {
+	switch val := value.(type) {
+	case map[interface{}]interface{}:
+		if val["enabled"] != nil {
+			if err := c.toBool(name+".enabled", val["enabled"], enabled); err != nil {
+				return err
+			}
+		}
+
+		return c.parseYAMLDockerSettings(name, val, dest)
+	case nil:
+		return nil
+	default:
+		return c.toBool(name, value, enabled)
+	}
+}
+
+func (c *Config) parseYAMLDockerSettings(name string, val map[interface{}]interface{}, dest *ConfigDockerSettings) error {
+	if err := c.toSequence(name+".args", val["args"], &dest.Args); err != nil {
+		return err
+	}
+
+	switch env := val["env"].(type) {
+	case map[interface{}]interface{}:
+		dest.Env = map[string]string{}
+		for k, v := range env {
+			key := fmt.Sprint(k)
+			var str string
+			if err := c.toString(name+".env."+key, v, &str); err != nil {
+				return err
+			}
+			dest.Env[key] = str
+		}
+	case nil:
+	default:
+		return c.errorExpected(name+".env", "mapping", env)
+	}
+
+	if val["image"] != nil {
+		if err := c.toString(name+".image", val["image"], &dest.Image); err != nil {
+			return err
+		}
+	}
+
+	if err := c.toSequence(name+".mounts", val["mounts"], &dest.Mounts); err != nil {
+		return err
+	}
+
+	if val["network"] != nil {
+		if err := c.toString(name+".network", val["network"], &dest.Network); err != nil {
+			return err
+		}
+	}
+
+	if val["user"] != nil {
+		if err := c.toString(name+".user", val["user"], &dest.User); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) parseYAMLDockerContainer(val map[interface{}]interface{}) error {
+	if val["container"] != nil {
+		var mode string
+		if err := c.toString("docker.container", val["container"], &mode); err != nil {
+			return err
+		}
+
+		m, err := tools.ParseContainerMode(mode)
+		if err != nil {
+			return c.errorValue("docker.container", err.Error())
+		}
+		c.Docker.Container = m
+	}
+
+	if val["idle_timeout"] != nil {
+		if err := c.toDuration("docker.idle_timeout", val["idle_timeout"], &c.Docker.IdleTimeout); err != nil {
+			return err
+		}
+
+		if c.Docker.IdleTimeout <= 0 {
+			return c.errorValue("docker.idle_timeout", "must be positive")
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) parseYAMLDockerRuntime(val map[interface{}]interface{}) error {
+	if val["runtime"] != nil {
+		var runtime string
+		if err := c.toString("docker.runtime", val["runtime"], &runtime); err != nil {
+			return err
+		}
+
+		r, err := tools.ParseRuntime(runtime)
+		if err != nil {
+			return c.errorValue("docker.runtime", err.Error())
+		}
+		c.Docker.Runtime = r
+		c.Docker.runtimeSet = true
+	}
+
+	if val["rootless"] != nil {
+		if err := c.toBool("docker.rootless", val["rootless"], &c.Docker.Rootless); err != nil {
+			return err
+		}
+		c.Docker.rootlessSet = true
+	}
+
+	return nil
+}
+
+func (c *Config) parseYAMLDocker() error {
+	switch val := c.yaml.Docker.(type) {
+	case map[interface{}]interface{}:
+		if err := c.parseYAMLDockerSettings("docker", val, &c.Docker.ConfigDockerSettings); err != nil {
+			return err
+		}
+
+		if val["bundle"] != nil {
+			if err := c.toString("docker.bundle", val["bundle"], &c.Docker.Bundle); err != nil {
+				return err
+			}
+		}
+
+		if err := c.parseYAMLDockerContainer(val); err != nil {
+			return err
+		}
+
+		if err := c.parseYAMLDockerRuntime(val); err != nil {
+			return err
+		}
+
+		return nil
+	case nil:
+		return nil
+	default:
+		return c.errorExpected("docker", "mapping", c.yaml.Docker)
+	}
+}
+
+// resolve detects the runtime and rootless mode unless they have been set.
+func (c *ConfigDocker) resolve() {
+	if !c.runtimeSet {
+		if r, ok := tools.DetectRuntime(); ok {
+			c.Runtime = r
+		}
+	}
+
+	if !c.rootlessSet {
+		// Podman is usually run rootless unless run as root
+		c.Rootless = c.Runtime == tools.RuntimePodman && os.Geteuid() != 0
+	}
+}
+
 func (c *Config) parseYAMLFormat() error {
 	switch val := c.yaml.Format.(type) {
 	case map[interface{}]interface{}:
@@ -192,12 +555,471 @@ func (c *Config) parseYAMLLint() error {
 	}
 }
 
+func (c *Config) parseYAMLTest() error {
+	switch val := c.yaml.Test.(type) {
+	case map[interface{}]interface{}:
+		if err := c.parseYAMLTool("test.busted", val["busted"], &c.Test.Busted); err != nil {
+			return err
+		}
+		return nil
+	case nil:
+		return nil
+	default:
+		return c.errorExpected("test", "mapping", c.yaml.Test)
+	}
+}
+
+// parseYAMLCustomToolCmd parses fields describing how a custom tool is run.
+func (c *Config) parseYAMLCustomToolCmd(name string, val map[interface{}]interface{}, tool *ConfigCustomTool) error {
+	fields := map[string]*string{
+		"cmd":   &tool.Cmd,
+		"image": &tool.Image,
+		"name":  &tool.Name,
+	}
+
+	for _, field := range []string{"cmd", "image", "name"} {
+		if val[field] != nil {
+			if err := c.toString(name+"."+field, val[field], fields[field]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(tool.Cmd) == 0 {
+		return c.errorValue(name+".cmd", "command is required")
+	}
+
+	sequences := map[string]*[]string{
+		"args":         &tool.Args,
+		"ext":          &tool.Ext,
+		"fix_args":     &tool.FixArgs,
+		"version_args": &tool.VersionArgs,
+	}
+
+	for _, field := range []string{"args", "ext", "fix_args", "version_args"} {
+		if err := c.toSequence(name+"."+field, val[field], sequences[field]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseYAMLCustomToolCapabilities parses capabilities of a custom tool. Only
+// lint and format are supported.
+func (c *Config) parseYAMLCustomToolCapabilities(name string, val map[interface{}]interface{}, tool *ConfigCustomTool) error {
+	var capabilities []string
+	if err := c.toSequence(name+".capabilities", val["capabilities"], &capabilities); err != nil {
+		return err
+	}
+
+	for _, str := range capabilities {
+		capability, err := tools.ParseCapability(str)
+		if err != nil {
+			return c.errorValue(name+".capabilities", err.Error())
+		}
+
+		if capability != tools.CapabilityLint && capability != tools.CapabilityFormat {
+			return c.errorValue(name+".capabilities", "only lint and format are supported")
+		}
+
+		tool.Capabilities |= capability
+	}
+
+	if tool.Capabilities == 0 {
+		return c.errorValue(name+".capabilities", "at least one capability is required")
+	}
+
+	return nil
+}
+
+// parseYAMLCustomToolOutput parses an output regex of a custom tool. It's
+// required for linting.
+func (c *Config) parseYAMLCustomToolOutput(name string, val map[interface{}]interface{}, tool *ConfigCustomTool) error {
+	if val["output"] != nil {
+		if err := c.toString(name+".output", val["output"], &tool.Output); err != nil {
+			return err
+		}
+
+		if _, err := regexp.Compile(tool.Output); err != nil {
+			return c.errorValue(name+".output", err.Error())
+		}
+	}
+
+	if tool.Capabilities.Has(tools.CapabilityLint) && len(tool.Output) == 0 {
+		return c.errorValue(name+".output", "output regex is required to lint")
+	}
+
+	return nil
+}
+
+func (c *Config) parseYAMLCustomTool(id string, value interface{}) (*ConfigCustomTool, error) {
+	name := "tools." + id
+
+	val, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, c.errorExpected(name, "mapping", value)
+	}
+
+	for _, r := range tools.Registrations() {
+		if r.ID == id {
+			return nil, c.errorValue(name, "conflicts with a built-in tool")
+		}
+	}
+
+	tool := &ConfigCustomTool{
+		ID:          id,
+		Name:        id,
+		VersionArgs: []string{"--version"},
+	}
+
+	if err := c.parseYAMLTool(name, val, &tool.ConfigTool); err != nil {
+		return nil, err
+	}
+
+	if val["enabled"] != nil {
+		if err := c.toBool(name+".enabled", val["enabled"], &tool.Enabled); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.parseYAMLCustomToolCmd(name, val, tool); err != nil {
+		return nil, err
+	}
+
+	if err := c.parseYAMLCustomToolCapabilities(name, val, tool); err != nil {
+		return nil, err
+	}
+
+	if err := c.parseYAMLCustomToolOutput(name, val, tool); err != nil {
+		return nil, err
+	}
+
+	return tool, nil
+}
+
+func (c *Config) parseYAMLTools() error {
+	switch val := c.yaml.Tools.(type) {
+	case map[interface{}]interface{}:
+		var ids []string
+		for k := range val {
+			ids = append(ids, fmt.Sprint(k))
+		}
+		sort.Strings(ids)
+
+		c.Tools = nil
+		for _, id := range ids {
+			tool, err := c.parseYAMLCustomTool(id, val[id])
+			if err != nil {
+				return err
+			}
+			c.Tools = append(c.Tools, *tool)
+		}
+
+		return nil
+	case nil:
+		return nil
+	default:
+		return c.errorExpected("tools", "mapping", c.yaml.Tools)
+	}
+}
+
+func (c *Config) parseYAMLVersions() error {
+	switch val := c.yaml.Versions.(type) {
+	case map[interface{}]interface{}:
+		c.Versions = map[string]string{}
+		for k, v := range val {
+			id := fmt.Sprint(k)
+			name := "versions." + id
+
+			if !c.isTool(id) {
+				return c.errorValue(name, "unknown tool")
+			}
+
+			var constraint string
+			if err := c.toString(name, v, &constraint); err != nil {
+				return err
+			}
+
+			if _, err := tools.ParseConstraint(constraint); err != nil {
+				return c.errorValue(name, err.Error())
+			}
+
+			c.Versions[id] = constraint
+		}
+		return nil
+	case nil:
+		return nil
+	default:
+		return c.errorExpected("versions", "mapping", c.yaml.Versions)
+	}
+}
+
+func (c *Config) parseYAMLWorkshopAssets(value interface{}) error {
+	switch val := value.(type) {
+	case map[interface{}]interface{}:
+		dest := &c.Workshop.Assets
+
+		fields := map[string]*string{
+			"cache": &dest.Cache,
+			"dest":  &dest.Dest,
+			"src":   &dest.Src,
+		}
+
+		for _, name := range []string{"cache", "dest", "src"} {
+			if val[name] != nil {
+				if err := c.toString("workshop.assets."+name, val[name], fields[name]); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := c.parseYAMLToolDocker("workshop.assets.docker", val["docker"], &dest.Docker, &dest.DockerSettings); err != nil {
+			return err
+		}
+
+		return nil
+	case nil:
+		return nil
+	default:
+		return c.errorExpected("workshop.assets", "mapping", value)
+	}
+}
+
+func (c *Config) parseYAMLWorkshopInstall(value interface{}) error {
+	switch val := value.(type) {
+	case map[interface{}]interface{}:
+		dest := &c.Workshop.Install
+
+		fields := map[string]*string{
+			"folder": &dest.Folder,
+			"target": &dest.Target,
+		}
+
+		for _, name := range []string{"folder", "target"} {
+			if val[name] != nil {
+				if err := c.toString("workshop.install."+name, val[name], fields[name]); err != nil {
+					return err
+				}
+			}
+		}
+
+		if val["symlink"] != nil {
+			if err := c.toBool("workshop.install.symlink", val["symlink"], &dest.Symlink); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case nil:
+		return nil
+	default:
+		return c.errorExpected("workshop.install", "mapping", value)
+	}
+}
+
+func (c *Config) parseYAMLWorkshopMinify(value interface{}) error {
+	name := "workshop.minify"
+	dest := &c.Workshop.Minify
+
+	switch val := value.(type) {
+	case map[interface{}]interface{}:
+		dest.Enabled = true
+
+		if val["enabled"] != nil {
+			if err := c.toBool(name+".enabled", val["enabled"], &dest.Enabled); err != nil {
+				return err
+			}
+		}
+
+		if val["keep_lines"] != nil {
+			if err := c.toBool(name+".keep_lines", val["keep_lines"], &dest.KeepLines); err != nil {
+				return err
+			}
+		}
+
+		if val["whitespace"] != nil {
+			if err := c.toBool(name+".whitespace", val["whitespace"], &dest.Whitespace); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case bool:
+		if err := c.toBool(name, val, &dest.Enabled); err != nil {
+			return err
+		}
+		return nil
+	case nil:
+		return nil
+	default:
+		return c.errorExpected(name, "bool or mapping", value)
+	}
+}
+
+func (c *Config) parseYAMLWorkshopPublish(value interface{}) error {
+	switch val := value.(type) {
+	case map[interface{}]interface{}:
+		dest := &c.Workshop.Publish
+
+		fields := map[string]*string{
+			"changelog": &dest.Changelog,
+			"id":        &dest.ID,
+			"preview":   &dest.Preview,
+			"steamcmd":  &dest.SteamCMD,
+			"username":  &dest.Username,
+		}
+
+		for _, name := range []string{"changelog", "id", "preview", "steamcmd", "username"} {
+			if val[name] != nil {
+				if err := c.toString("workshop.publish."+name, val[name], fields[name]); err != nil {
+					return err
+				}
+			}
+		}
+
+		if val["visibility"] != nil {
+			if err := c.toInt("workshop.publish.visibility", val["visibility"], &dest.Visibility); err != nil {
+				return err
+			}
+			if dest.Visibility < 0 || dest.Visibility > 3 {
+				return c.errorValue("workshop.publish.visibility", "must be between 0 and 3")
+			}
+		}
+
+		return nil
+	case nil:
+		return nil
+	default:
+		return c.errorExpected("workshop.publish", "mapping", value)
+	}
+}
+
+func (c *Config) parseYAMLWorkshopTransform(name string, value interface{}) (*ConfigWorkshopTransform, error) {
+	val, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, c.errorExpected(name, "mapping", value)
+	}
+
+	transform := &ConfigWorkshopTransform{
+		Vars: map[string]string{},
+	}
+
+	if err := c.toSequence(name+".files", val["files"], &transform.Files); err != nil {
+		return nil, err
+	}
+
+	if len(transform.Files) == 0 {
+		return nil, c.errorValue(name+".files", "at least one glob is required")
+	}
+
+	switch vars := val["vars"].(type) {
+	case map[interface{}]interface{}:
+		for k, v := range vars {
+			key := fmt.Sprint(k)
+			var str string
+			if err := c.toString(name+".vars."+key, v, &str); err != nil {
+				return nil, err
+			}
+			transform.Vars[key] = str
+		}
+	case nil:
+	default:
+		return nil, c.errorExpected(name+".vars", "mapping", vars)
+	}
+
+	return transform, nil
+}
+
+func (c *Config) parseYAMLWorkshopTransforms(value interface{}) error {
+	switch val := value.(type) {
+	case []interface{}:
+		c.Workshop.Transforms = nil
+		for i, v := range val {
+			transform, err := c.parseYAMLWorkshopTransform(fmt.Sprintf("workshop.transforms[%d]", i), v)
+			if err != nil {
+				return err
+			}
+			c.Workshop.Transforms = append(c.Workshop.Transforms, *transform)
+		}
+		return nil
+	case nil:
+		return nil
+	default:
+		return c.errorExpected("workshop.transforms", "null or sequence", value)
+	}
+}
+
+func (c *Config) parseYAMLWorkshopFormat(value interface{}) error {
+	if err := c.toString("workshop.format", value, &c.Workshop.Format); err != nil {
+		return err
+	}
+
+	if _, err := workshop.ParseFormat(c.Workshop.Format); err != nil {
+		return c.errorValue("workshop.format", err.Error())
+	}
+
+	return nil
+}
+
+// yamlWorkshopParsers returns parsers for each "workshop" key.
+func (c *Config) yamlWorkshopParsers() map[string]func(value interface{}) error {
+	dest := &c.Workshop
+	return map[string]func(value interface{}) error{
+		"assets": c.parseYAMLWorkshopAssets,
+		"compression": func(value interface{}) error {
+			return c.toInt("workshop.compression", value, &dest.Compression)
+		},
+		"format": c.parseYAMLWorkshopFormat,
+		"ignore": func(value interface{}) error {
+			return c.toSequence("workshop.ignore", value, &dest.Ignore)
+		},
+		"include": func(value interface{}) error {
+			return c.toSequence("workshop.include", value, &dest.Include)
+		},
+		"install": c.parseYAMLWorkshopInstall,
+		"jobs": func(value interface{}) error {
+			return c.toInt("workshop.jobs", value, &dest.Jobs)
+		},
+		"max_size": func(value interface{}) error {
+			return c.toSize("workshop.max_size", value, &dest.MaxSize)
+		},
+		"minify": c.parseYAMLWorkshopMinify,
+		"out": func(value interface{}) error {
+			return c.toString("workshop.out", value, &dest.Out)
+		},
+		"publish": c.parseYAMLWorkshopPublish,
+		"reproducible": func(value interface{}) error {
+			return c.toBool("workshop.reproducible", value, &dest.Reproducible)
+		},
+		"transforms": c.parseYAMLWorkshopTransforms,
+		"validate": func(value interface{}) error {
+			return c.toBool("workshop.validate", value, &dest.Validate)
+		},
+	}
+}
+
 func (c *Config) parseYAMLWorkshop() error {
 	switch val := c.yaml.Workshop.(type) {
 	case map[interface{}]interface{}:
-		if err := c.toSequence("workshop.ignore", val["ignore"], &c.Workshop.Ignore); err != nil {
-			return err
+		parsers := c.yamlWorkshopParsers()
+
+		names := make([]string, 0, len(parsers))
+		for name := range parsers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if val[name] == nil {
+				continue
+			}
+
+			if err := parsers[name](val[name]); err != nil {
+				return err
+			}
 		}
+
 		return nil
 	case nil:
 		return nil
@@ -215,6 +1037,10 @@ func (c *Config) load(file *os.File) error {
 	c.file = file
 	c.yaml = *yml
 
+	if err := c.parseYAMLDocker(); err != nil {
+		return err
+	}
+
 	if err := c.parseYAMLFormat(); err != nil {
 		return err
 	}
@@ -223,6 +1049,24 @@ func (c *Config) load(file *os.File) error {
 		return err
 	}
 
+	if err := c.parseYAMLTest(); err != nil {
+		return err
+	}
+
+	if c.yaml.Jobs != nil {
+		if err := c.toInt("jobs", c.yaml.Jobs, &c.Jobs); err != nil {
+			return err
+		}
+	}
+
+	if err := c.parseYAMLTools(); err != nil {
+		return err
+	}
+
+	if err := c.parseYAMLVersions(); err != nil {
+		return err
+	}
+
 	if err := c.parseYAMLWorkshop(); err != nil {
 		return err
 	}
@@ -230,9 +1074,61 @@ func (c *Config) load(file *os.File) error {
 	return nil
 }
 
+// isTool checks if a built-in or custom tool with the provided ID exists.
+func (c *Config) isTool(id string) bool {
+	for _, r := range tools.Registrations() {
+		if r.ID == id {
+			return true
+		}
+	}
+
+	for _, t := range c.Tools {
+		if t.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// toolConfig returns a configuration of the tool with the provided ID or nil
+// if the tool isn't configurable. For ktech, only the Docker settings are
+// taken from "workshop.assets".
+func (c *Config) toolConfig(id string) *ConfigTool {
+	switch id {
+	case "busted":
+		return &c.Test.Busted
+	case "ktech":
+		return &ConfigTool{
+			Docker:         c.Workshop.Assets.Docker,
+			DockerSettings: c.Workshop.Assets.DockerSettings,
+			Enabled:        true,
+		}
+	case "luacheck":
+		return &c.Lint.Luacheck
+	case "prettier":
+		return &c.Format.Prettier
+	case "stylua":
+		return &c.Format.StyLua
+	}
+
+	for i := range c.Tools {
+		if c.Tools[i].ID == id {
+			return &c.Tools[i].ConfigTool
+		}
+	}
+
+	return nil
+}
+
 type ConfigYAML struct {
+	Docker   interface{} `yaml:"docker"`
 	Format   interface{} `yaml:"format"`
+	Jobs     interface{} `yaml:"jobs"`
 	Lint     interface{} `yaml:"lint"`
+	Test     interface{} `yaml:"test"`
+	Tools    interface{} `yaml:"tools"`
+	Versions interface{} `yaml:"versions"`
 	Workshop interface{} `yaml:"workshop"`
 }
 
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// loadTestConfig loads a configuration from a file with the provided YAML.
+func loadTestConfig(t *testing.T, yaml string) (*Config, error) {
+	path := filepath.Join(t.TempDir(), ".modcli")
+	assert.Nil(t, os.WriteFile(path, []byte(yaml), 0o600))
+
+	file, err := os.Open(path)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	cfg := NewConfig()
+	return cfg, cfg.load(file)
+}
+
+func TestConfig_parseYAMLWorkshopPublish(t *testing.T) {
+	cfg := NewConfig()
+	assert.Nil(t, cfg.parseYAMLWorkshopPublish(map[interface{}]interface{}{
+		"changelog":  "CHANGELOG.md",
+		"id":         "1234567890",
+		"preview":    "preview.png",
+		"steamcmd":   "steamcmd",
+		"username":   "user",
+		"visibility": 2,
+	}))
+
+	assert.Equal(t, ConfigWorkshopPublish{
+		Changelog:  "CHANGELOG.md",
+		ID:         "1234567890",
+		Preview:    "preview.png",
+		SteamCMD:   "steamcmd",
+		Username:   "user",
+		Visibility: 2,
+	}, cfg.Workshop.Publish)
+
+	assert.Nil(t, cfg.parseYAMLWorkshopPublish(nil))
+	assert.EqualError(
+		t,
+		cfg.parseYAMLWorkshopPublish("public"),
+		"invalid YAML workshop.publish value: expected mapping but got string",
+	)
+}
+
+func TestConfig_parseYAMLWorkshopPublish_Visibility(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		err   string
+	}{
+		{0, ""},
+		{1, ""},
+		{2, ""},
+		{3, ""},
+		{-1, "invalid YAML workshop.publish.visibility value: must be between 0 and 3"},
+		{4, "invalid YAML workshop.publish.visibility value: must be between 0 and 3"},
+		{"private", "invalid YAML workshop.publish.visibility value: expected int but got string"},
+	}
+
+	for _, tt := range tests {
+		err := NewConfig().parseYAMLWorkshopPublish(map[interface{}]interface{}{"visibility": tt.value})
+		if len(tt.err) == 0 {
+			assert.Nilf(t, err, "Visibility %v", tt.value)
+			continue
+		}
+		assert.EqualErrorf(t, err, tt.err, "Visibility %v", tt.value)
+	}
+}
+
+func TestConfig_load_Visibility(t *testing.T) {
+	cfg, err := loadTestConfig(t, "workshop:\n  publish:\n    visibility: 3\n")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, cfg.Workshop.Publish.Visibility)
+
+	_, err = loadTestConfig(t, "workshop:\n  publish:\n    visibility: 5\n")
+	assert.EqualError(t, err, "invalid YAML workshop.publish.visibility value: must be between 0 and 3")
+}
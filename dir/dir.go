@@ -3,8 +3,11 @@
 package dir
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/mattn/go-zglob"
@@ -14,18 +17,22 @@ import (
 type Controller interface {
 	SetIgnore([]string)
 	Ignore() []string
+	SetInclude([]string)
+	Include() []string
 	AbsPath() string
 	RelPath() string
 	Base() string
 	Dir() string
 	IsPathIgnored(string) bool
 	ListFiles(...string) ([]string, int64, error)
+	ExpandPaths([]string, ...string) ([]string, error)
 }
 
 // Dir represents a working directory.
 type Dir struct {
 	absPath string
 	ignore  []string
+	include []string
 	relPath string
 }
 
@@ -57,6 +64,16 @@ func (d *Dir) Ignore() []string {
 	return d.ignore
 }
 
+// SetInclude sets include list. Paths matching it are never ignored.
+func (d *Dir) SetInclude(include []string) {
+	d.include = include
+}
+
+// Include gets include list.
+func (d *Dir) Include() []string {
+	return d.include
+}
+
 // AbsPath returns an absolute path.
 func (d *Dir) AbsPath() string {
 	return d.absPath
@@ -77,23 +94,61 @@ func (d *Dir) Dir() string {
 	return filepath.Dir(d.absPath)
 }
 
-// IsPathIgnored checks if the provided path is ignored based on ignore.
+// MatchPath checks if the provided path matches a pattern the same way as in
+// gitignore: a pattern prefixed with "/" is anchored to the root and matches
+// everything inside when it's a directory.
+func MatchPath(pattern, path string) bool {
+	hasPrefix := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	prefix := "**/"
+	if hasPrefix {
+		prefix = ""
+	}
+
+	matched, _ := zglob.Match(prefix+pattern+"**/*", path)
+	return matched
+}
+
+// IsPathIgnored checks if the provided path is ignored based on ignore and
+// include. Like in gitignore, a pattern prefixed with "!" re-includes a path
+// ignored by one of the previous patterns and the last matching pattern wins.
+// Paths matching include are never ignored.
 func (d *Dir) IsPathIgnored(path string) bool {
+	for _, include := range d.include {
+		if MatchPath(include, path) {
+			return false
+		}
+	}
+
+	ignored := false
 	for _, ignore := range d.ignore {
-		hasPrefix := strings.HasPrefix(ignore, "/")
-		ignore = strings.TrimPrefix(ignore, "/")
-		ignore = strings.TrimSuffix(ignore, "/")
+		negate := strings.HasPrefix(ignore, "!")
+		if negate {
+			ignore = strings.TrimPrefix(ignore, "!")
+		} else {
+			ignore = strings.TrimPrefix(ignore, "\\")
+		}
 
-		prefix := "**/"
-		if hasPrefix {
-			prefix = ""
+		if MatchPath(ignore, path) {
+			ignored = !negate
 		}
+	}
+	return ignored
+}
+
+func hasExt(path string, ext []string) bool {
+	if len(ext) == 0 {
+		return true
+	}
 
-		matched, _ := zglob.Match(prefix+ignore+"**/*", path)
-		if matched {
+	for _, e := range ext {
+		if filepath.Ext(path) == e {
 			return true
 		}
 	}
+
 	return false
 }
 
@@ -104,23 +159,10 @@ func (d *Dir) ListFiles(ext ...string) (path []string, size int64, err error) {
 			return err
 		}
 
-		if i.IsDir() || d.IsPathIgnored(p) {
+		if i.IsDir() || d.IsPathIgnored(p) || !hasExt(p, ext) {
 			return nil
 		}
 
-		if len(ext) > 0 {
-			isFound := false
-			for _, e := range ext {
-				if filepath.Ext(p) == e {
-					isFound = true
-				}
-			}
-
-			if !isFound {
-				return nil
-			}
-		}
-
 		path = append(path, p)
 		size += i.Size()
 		return nil
@@ -129,3 +171,78 @@ func (d *Dir) ListFiles(ext ...string) (path []string, size int64, err error) {
 	}
 	return path, size, err
 }
+
+// rel returns a path relative to the directory. It fails when the path is
+// outside of it.
+func (d *Dir) rel(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(d.absPath, absPath)
+	if err != nil {
+		return "", err
+	}
+
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside of %s", path, d.absPath)
+	}
+
+	return rel, nil
+}
+
+// ExpandPaths expands the provided files, directories and glob patterns like
+// "scripts/**/*.lua" into a list of files based on ignore. Like in ListFiles,
+// only files with one of the provided extensions are kept unless none are
+// provided. Returned paths are relative and each of them is listed only once.
+func (d *Dir) ExpandPaths(paths []string, ext ...string) (result []string, err error) {
+	seen := map[string]bool{}
+
+	add := func(path string) error {
+		rel, err := d.rel(path)
+		if err != nil {
+			return err
+		}
+
+		if !seen[rel] && !d.IsPathIgnored(rel) && hasExt(rel, ext) {
+			seen[rel] = true
+			result = append(result, rel)
+		}
+
+		return nil
+	}
+
+	for _, path := range paths {
+		matches, err := zglob.Glob(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return result, fmt.Errorf("no such file or directory: %s", path)
+		} else if err != nil {
+			return result, err
+		}
+
+		if len(matches) == 0 {
+			return result, fmt.Errorf("no files match %s", path)
+		}
+
+		sort.Strings(matches)
+
+		for _, match := range matches {
+			if err := filepath.Walk(match, func(p string, i os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if i.IsDir() {
+					return nil
+				}
+
+				return add(p)
+			}); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -1,6 +1,8 @@
 package dir
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -67,3 +69,128 @@ func TestWorkshop_IsPathIgnored(t *testing.T) {
 	d.ignore = []string{"/.git"}
 	assertIsPathIgnored(t, d, testCases)
 }
+
+func TestWorkshop_IsPathIgnored_Negation(t *testing.T) {
+	d, _ := New(".")
+
+	testCases := map[string]bool{
+		"docs/":             true,
+		"docs/index.md":     true,
+		"docs/api/index.md": true,
+		"docs/LICENSE":      false,
+		"LICENSE":           false,
+		"modmain.lua":       false,
+	}
+
+	d.ignore = []string{"docs/", "!docs/LICENSE"}
+	assertIsPathIgnored(t, d, testCases)
+
+	// the last matching pattern wins
+	testCases = map[string]bool{
+		"docs/index.md": true,
+		"docs/LICENSE":  true,
+	}
+
+	d.ignore = []string{"docs/", "!docs/LICENSE", "docs/"}
+	assertIsPathIgnored(t, d, testCases)
+
+	// escaped "!"
+	testCases = map[string]bool{
+		"!important.md": true,
+		"important.md":  false,
+	}
+
+	d.ignore = []string{"\\!important.md"}
+	assertIsPathIgnored(t, d, testCases)
+}
+
+func TestWorkshop_IsPathIgnored_Include(t *testing.T) {
+	d, _ := New(".")
+
+	testCases := map[string]bool{
+		"docs/index.md":     true,
+		"docs/LICENSE":      false,
+		"README.md":         true,
+		"scripts/README.md": false,
+	}
+
+	d.ignore = []string{"docs/", "*.md"}
+	d.include = []string{"docs/LICENSE", "/scripts/"}
+	assertIsPathIgnored(t, d, testCases)
+
+	// include takes precedence over ignore
+	d.ignore = []string{"docs/", "*.md", "LICENSE"}
+	assertIsPathIgnored(t, d, testCases)
+}
+
+func TestMatchPath(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		path    string
+		result  bool
+	}{
+		{"modmain.lua", "modmain.lua", true},
+		{"modmain.lua", "scripts/modmain.lua", true},
+		{"/modmain.lua", "scripts/modmain.lua", false},
+		{"scripts/", "scripts/foo/bar.lua", true},
+		{"scripts/**/*.lua", "scripts/foo/bar.lua", true},
+		{"*.lua", "modinfo.lua", true},
+		{"*.lua", "modicon.tex", false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equalf(t, tc.result, MatchPath(tc.pattern, tc.path), `Pattern "%s" and path "%s"`, tc.pattern, tc.path)
+	}
+}
+
+func TestDir_ExpandPaths(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(wd) }()
+
+	tmp := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(tmp, "outside.lua"), nil, 0o600))
+
+	tmp = filepath.Join(tmp, "mod")
+	for _, path := range []string{
+		"modmain.lua",
+		"README.md",
+		"scripts/foo.lua",
+		"scripts/bar/baz.lua",
+		"scripts/vendor/lib.lua",
+	} {
+		assert.Nil(t, os.MkdirAll(filepath.Join(tmp, filepath.Dir(path)), 0o755))
+		assert.Nil(t, os.WriteFile(filepath.Join(tmp, path), nil, 0o600))
+	}
+	assert.Nil(t, os.Chdir(tmp))
+
+	d, _ := New(".")
+	d.ignore = []string{"vendor/"}
+
+	testCases := []struct {
+		paths  []string
+		ext    []string
+		result []string
+	}{
+		{[]string{"modmain.lua"}, nil, []string{"modmain.lua"}},
+		{[]string{"README.md", "modmain.lua"}, []string{".lua"}, []string{"modmain.lua"}},
+		{[]string{"scripts"}, nil, []string{"scripts/bar/baz.lua", "scripts/foo.lua"}},
+		{[]string{"scripts/vendor/lib.lua"}, nil, nil},
+		{[]string{"**/*.lua"}, nil, []string{"modmain.lua", "scripts/bar/baz.lua", "scripts/foo.lua"}},
+		{[]string{"scripts/foo.lua", "./scripts"}, nil, []string{"scripts/foo.lua", "scripts/bar/baz.lua"}},
+	}
+
+	for _, tc := range testCases {
+		result, err := d.ExpandPaths(tc.paths, tc.ext...)
+		assert.Nil(t, err)
+		assert.Equalf(t, tc.result, result, "Paths %v", tc.paths)
+	}
+
+	_, err := d.ExpandPaths([]string{"missing.lua"})
+	assert.EqualError(t, err, "no such file or directory: missing.lua")
+
+	_, err = d.ExpandPaths([]string{"**/*.tex"})
+	assert.EqualError(t, err, "no files match **/*.tex")
+
+	_, err = d.ExpandPaths([]string{"../outside.lua"})
+	assert.EqualError(t, err, "../outside.lua is outside of "+tmp)
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/dstmodders/mod-cli/tools"
 	"github.com/fatih/color"
@@ -9,8 +11,9 @@ import (
 )
 
 type Doctor struct {
-	cfg   *Config
-	tools *tools.Tools
+	cfg         *Config
+	tools       *tools.Tools
+	versionErrs []error
 }
 
 func NewDoctor(cfg *Config) *Doctor {
@@ -47,13 +50,57 @@ func (d *Doctor) printIgnore(ignores []string) {
 	}
 }
 
+func (d *Doctor) printInclude(includes []string) {
+	if len(includes) == 0 {
+		fmt.Printf("%s: -\n", "Include")
+		return
+	}
+
+	fmt.Printf("%s:\n\n", "Include")
+	for _, include := range includes {
+		fmt.Printf("  %s\n", include)
+	}
+}
+
+func (d *Doctor) printDockerSettings(cfg ConfigDockerSettings) {
+	if len(cfg.Image) > 0 {
+		printNameValue("Image", cfg.Image)
+	}
+
+	if len(cfg.User) > 0 {
+		printNameValue("User", cfg.User)
+	}
+
+	if len(cfg.Network) > 0 {
+		printNameValue("Network", cfg.Network)
+	}
+
+	if len(cfg.Mounts) > 0 {
+		printNameValue("Mounts", strings.Join(cfg.Mounts, ", "))
+	}
+
+	if len(cfg.Env) > 0 {
+		keys := make([]string, 0, len(cfg.Env))
+		for key := range cfg.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		printNameValue("Env", strings.Join(keys, ", "))
+	}
+
+	if len(cfg.Args) > 0 {
+		printNameValue("Args", strings.Join(cfg.Args, " "))
+	}
+}
+
 func (d *Doctor) printConfigLintTool(cfg ConfigTool) {
 	printNameValue("Enabled", cfg.Enabled)
 	printNameValue("Dockerized", cfg.Docker)
+	d.printDockerSettings(cfg.DockerSettings)
 	d.printIgnore(cfg.Ignore)
 }
 
-func (d *Doctor) printTool(tool tools.Tooler) {
+func (d *Doctor) printTool(id string, tool tools.Tooler) {
 	name := tool.Name()
 	path := tool.Path()
 
@@ -67,7 +114,18 @@ func (d *Doctor) printTool(tool tools.Tooler) {
 		ver = "-"
 	}
 
-	printNameValue(name, fmt.Sprintf("%s | %s", path, ver))
+	value := fmt.Sprintf("%s | %s", path, ver)
+
+	if constraint, ok := d.cfg.Versions[id]; ok && ver != "-" {
+		if err := tools.CheckVersion(tool, constraint); err != nil {
+			d.versionErrs = append(d.versionErrs, err)
+			value = fmt.Sprintf("%s | %s", value, color.RedString("required %s", constraint))
+		} else {
+			value = fmt.Sprintf("%s | %s", value, color.GreenString(constraint))
+		}
+	}
+
+	printNameValue(name, value)
 }
 
 func (d *Doctor) printTools() {
@@ -75,17 +133,13 @@ func (d *Doctor) printTools() {
 
 	printTitle("Tools | System")
 
+	t.SetToolsRunInDocker(false)
 	t.LookPaths()
 	t.LoadVersions()
 
-	d.printTool(t.Busted)
-	d.printTool(t.Docker)
-	d.printTool(t.LDoc)
-	d.printTool(t.Luacheck)
-	d.printTool(t.Prettier)
-	d.printTool(t.StyLua)
-	d.printTool(t.Krane)
-	d.printTool(t.Ktech)
+	for _, id := range t.IDs() {
+		d.printTool(id, t.Get(id))
+	}
 
 	if !t.Docker.ExistsOnSystem() {
 		return
@@ -98,17 +152,24 @@ func (d *Doctor) printTools() {
 	t.LookPaths()
 	t.LoadVersions()
 
-	d.printTool(t.Busted)
-	d.printTool(t.LDoc)
-	d.printTool(t.Luacheck)
-	d.printTool(t.Prettier)
-	d.printTool(t.StyLua)
-	d.printTool(t.Krane)
-	d.printTool(t.Ktech)
+	for _, id := range t.IDs() {
+		if r, ok := t.Registration(id); ok && r.Dockerized {
+			d.printTool(id, t.Get(id))
+		}
+	}
+}
+
+func (d *Doctor) printConfigCustomTool(cfg ConfigCustomTool) {
+	printNameValue("Command", strings.Join(append([]string{cfg.Cmd}, cfg.Args...), " "))
+	printNameValue("Capabilities", cfg.Capabilities.String())
+	if len(cfg.Image) > 0 {
+		printNameValue("Image", cfg.Image)
+	}
+	d.printConfigLintTool(cfg.ConfigTool)
 }
 
 func (d *Doctor) print() error {
-	t, err := tools.New()
+	t, err := newTools(d.cfg)
 	if err != nil {
 		return err
 	}
@@ -120,6 +181,17 @@ func (d *Doctor) print() error {
 	d.printTools()
 	fmt.Println()
 
+	printTitle("Docker")
+	printNameValue("Runtime", d.cfg.Docker.Runtime.String())
+	printNameValue("Rootless", d.cfg.Docker.Rootless)
+	printNameValue("Container", d.cfg.Docker.Container.String())
+	printNameValue("Idle timeout", d.cfg.Docker.IdleTimeout.String())
+	if len(d.cfg.Docker.Bundle) > 0 {
+		printNameValue("Bundle", d.cfg.Docker.Bundle)
+	}
+	d.printDockerSettings(d.cfg.Docker.ConfigDockerSettings)
+	fmt.Println()
+
 	printTitle("Format | Prettier")
 	d.printConfigLintTool(d.cfg.Format.Prettier)
 	fmt.Println()
@@ -132,8 +204,16 @@ func (d *Doctor) print() error {
 	d.printConfigLintTool(d.cfg.Lint.Luacheck)
 	fmt.Println()
 
+	for _, tool := range d.cfg.Tools {
+		printTitle("Tools | " + tool.Name)
+		d.printConfigCustomTool(tool)
+		fmt.Println()
+	}
+
 	printTitle("Workshop")
 	d.printIgnore(d.cfg.Workshop.Ignore)
+	fmt.Println()
+	d.printInclude(d.cfg.Workshop.Include)
 
 	return nil
 }
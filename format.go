@@ -3,34 +3,25 @@ package main
 import (
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/dstmodders/mod-cli/tools"
 	"github.com/fatih/color"
 )
 
 type Format struct {
-	canRunPrettier bool
-	canRunStyLua   bool
-	cfg            *Config
-	tools          *tools.Tools
+	Paths  []string
+	canRun []string
+	cfg    *Config
+	tools  *tools.Tools
 }
 
 func NewFormat(cfg *Config) (*Format, error) {
-	t, err := tools.New()
+	t, err := newTools(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	t.Prettier.SetIgnore(cfg.Format.Prettier.Ignore)
-	if cfg.Format.Prettier.Docker {
-		t.Prettier.SetRunInDocker(true)
-	}
-
-	t.StyLua.SetIgnore(cfg.Format.StyLua.Ignore)
-	if cfg.Format.StyLua.Docker {
-		t.StyLua.SetRunInDocker(true)
-	}
-
 	return &Format{
 		cfg:   cfg,
 		tools: t,
@@ -38,48 +29,12 @@ func NewFormat(cfg *Config) (*Format, error) {
 }
 
 func (f *Format) checkTools() {
-	var errPrettier, errStyLua error
-
-	err := errors.New("neither Prettier nor StyLua are available")
-
-	if !f.cfg.Format.Prettier.Enabled && !f.cfg.Format.StyLua.Enabled {
-		fatalError("both Prettier and StyLua are disabled. Enable at least one of them first")
-	}
-
-	if f.cfg.Format.Prettier.Enabled {
-		errPrettier = checkIfToolExists(f.tools.Docker, f.tools.Prettier)
-		if errPrettier == nil {
-			f.canRunPrettier = true
-			err = nil
-		}
-	}
-
-	if f.cfg.Format.StyLua.Enabled {
-		errStyLua = checkIfToolExists(f.tools.Docker, f.tools.StyLua)
-		if errStyLua == nil {
-			f.canRunStyLua = true
-			err = nil
-		}
-	}
-
-	if f.canRunStyLua || f.canRunPrettier {
-		if errPrettier != nil {
-			printWarning(errPrettier)
-		}
-
-		if errStyLua != nil {
-			printWarning(errStyLua)
-		}
-	}
-
-	if err != nil {
-		fatalError(err)
-	}
+	f.canRun = checkCapabilityTools(f.tools, f.cfg, tools.CapabilityFormat)
 }
 
-func (f *Format) printFormat(format tools.Format) {
+func (f *Format) printFormat(w io.Writer, format tools.Format) {
 	if len(format.Files) == 0 {
-		fmt.Println("No issues found")
+		fmt.Fprintln(w, "No issues found")
 		return
 	}
 
@@ -92,57 +47,54 @@ func (f *Format) printFormat(format tools.Format) {
 		default:
 			state = color.YellowString("warning")
 		}
-		fmt.Printf("%s %s\n", state, file.Path)
+		fmt.Fprintf(w, "%s %s\n", state, file.Path)
 	}
 }
 
-func (f *Format) runPrettier() (err error) {
+func (f *Format) runTool(w io.Writer, id string, tool tools.Tooler) (err error) {
 	var format tools.Format
 
-	if f.cfg.Format.Prettier.Fix {
-		format, err = f.tools.Prettier.Fix()
+	formatter, ok := tool.(tools.Formatter)
+	if !ok {
+		return fmt.Errorf("%s is not a formatter", id)
+	}
+
+	if f.fixes(id) {
+		format, err = formatter.Fix(f.Paths...)
 	} else {
-		format, err = f.tools.Prettier.Check()
+		format, err = formatter.Check(f.Paths...)
 	}
 
-	if err != nil {
+	var toolErr *tools.ToolError
+	if err != nil && !errors.As(err, &toolErr) {
 		return err
 	}
 
-	printTitle("Prettier")
-	f.printFormat(format)
-	return nil
-}
+	fprintTitle(w, formatter.Name())
 
-func (f *Format) runStyLua() (err error) {
-	var format tools.Format
-
-	if f.cfg.Format.StyLua.Fix {
-		format, err = f.tools.StyLua.Fix()
-	} else {
-		format, err = f.tools.StyLua.Check()
+	if toolErr != nil {
+		return err
 	}
 
-	if err != nil {
-		return err
+	f.printFormat(w, format)
+
+	if format.HasIssues() {
+		return errToolIssues
 	}
 
-	printTitle("StyLua")
-	f.printFormat(format)
 	return nil
 }
 
+// fixes checks if the tool is going to fix files instead of only checking them.
+func (f *Format) fixes(id string) bool {
+	cfg := f.cfg.toolConfig(id)
+	return cfg != nil && cfg.Fix
+}
+
 func (f *Format) run() {
 	f.checkTools()
 
-	if f.canRunPrettier && f.cfg.Format.Prettier.Enabled {
-		_ = f.runPrettier()
-	}
-
-	if f.canRunStyLua && f.cfg.Format.StyLua.Enabled {
-		if f.canRunPrettier && f.cfg.Format.Prettier.Enabled {
-			fmt.Println()
-		}
-		_ = f.runStyLua()
+	if code := runTools(f.tools, f.canRun, f.fixes, f.runTool); code != 0 {
+		exit(code)
 	}
 }
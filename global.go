@@ -1,13 +1,29 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/dstmodders/mod-cli/tools"
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// Exit codes.
+const (
+	exitCodeError      = 1
+	exitCodeValidation = 2
+	exitCodeDestExists = 3
+	exitCodeCanceled   = 4
+	exitCodeVersion    = 5
 )
 
 func printError(err interface{}, args ...interface{}) {
@@ -44,11 +60,428 @@ func printWarning(err interface{}, args ...interface{}) {
 }
 
 func fatalError(err interface{}, args ...interface{}) {
+	fatalErrorWithCode(exitCodeError, err, args...)
+}
+
+func fatalErrorWithCode(code int, err interface{}, args ...interface{}) {
 	printError(err, args...)
-	os.Exit(1)
+	exit(code)
+}
+
+// exit exits with the provided code after calling all functions registered
+// using atExit.
+func exit(code int) {
+	runExitHooks()
+	os.Exit(code)
+}
+
+var exitHooks []func()
+var exitHooksMu sync.Mutex
+
+// atExit registers a function to be called before exiting, including on fatal
+// errors and interrupts. The functions are called in reverse order.
+func atExit(fn func()) {
+	exitHooksMu.Lock()
+	defer exitHooksMu.Unlock()
+	exitHooks = append(exitHooks, fn)
+}
+
+// runExitHooks calls and removes all functions registered using atExit.
+func runExitHooks() {
+	exitHooksMu.Lock()
+	hooks := exitHooks
+	exitHooks = nil
+	exitHooksMu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+func joinNames(names []string) string {
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// newTools creates tools including the custom ones declared in the
+// configuration. Each configurable tool gets its ignore list and, when
+// enabled, is set to run in Docker.
+func newTools(cfg *Config) (*tools.Tools, error) {
+	t, err := tools.New()
+	if err != nil {
+		return nil, err
+	}
+
+	t.SetJobs(cfg.Jobs)
+
+	if err := addCustomTools(t, cfg); err != nil {
+		return nil, err
+	}
+
+	for _, id := range t.IDs() {
+		applyToolConfig(t, cfg, id)
+	}
+
+	t.Docker.Bundle = cfg.Docker.Bundle
+	t.SetRuntime(cfg.Docker.Runtime, cfg.Docker.Rootless)
+	t.SetContainerMode(cfg.Docker.Container, cfg.Docker.IdleTimeout)
+	atExit(func() {
+		_ = t.Close()
+	})
+
+	return t, nil
+}
+
+// addCustomTools adds the custom tools declared in the configuration.
+func addCustomTools(t *tools.Tools, cfg *Config) error {
+	for _, ct := range cfg.Tools {
+		ct := ct
+		err := t.Add(tools.Registration{
+			ID:           ct.ID,
+			Capabilities: ct.Capabilities,
+			Dockerized:   len(ct.Image) > 0 || ct.Docker || len(ct.DockerSettings.Image) > 0,
+			DockerImage:  ct.Image,
+			New: func() (tools.Tooler, error) {
+				tool, err := tools.NewCustom(ct.Name, ct.Cmd)
+				if err != nil {
+					return nil, err
+				}
+
+				tool.Args = ct.Args
+				tool.Ext = ct.Ext
+				tool.FixArgs = ct.FixArgs
+				tool.VersionArgs = ct.VersionArgs
+
+				if len(ct.Output) > 0 {
+					tool.Output, err = regexp.Compile(ct.Output)
+					if err != nil {
+						return nil, err
+					}
+				}
+
+				return tool, nil
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyToolConfig applies the global Docker settings followed by the tool
+// ones to the tool with the provided ID along with its ignore list.
+func applyToolConfig(t *tools.Tools, cfg *Config, id string) {
+	tool := t.Get(id)
+	d := tool.Dockerized()
+
+	applyDockerSettings(d, cfg.Docker.ConfigDockerSettings)
+	if r, ok := t.Registration(id); ok && len(r.DockerImage) > 0 {
+		d.Image = r.DockerImage
+	}
+
+	if tc := cfg.toolConfig(id); tc != nil {
+		applyDockerSettings(d, tc.DockerSettings)
+		tool.SetIgnore(tc.Ignore)
+		if tc.Docker {
+			tool.SetRunInDocker(true)
+		}
+	}
+}
+
+// applyDockerSettings applies the settings from the configuration. The image,
+// user and network replace the current ones while the arguments, environment
+// variables and mounts are added. Relative mount sources are resolved against
+// the working directory.
+func applyDockerSettings(d *tools.Dockerized, s ConfigDockerSettings) {
+	if len(s.Image) > 0 {
+		d.Image = s.Image
+	}
+
+	if len(s.User) > 0 {
+		d.User = s.User
+	}
+
+	if len(s.Network) > 0 {
+		d.Network = s.Network
+	}
+
+	d.ExtraArgs = append(d.ExtraArgs, s.Args...)
+
+	for _, mount := range s.Mounts {
+		if strings.HasPrefix(mount, ".") {
+			split := strings.SplitN(mount, ":", 2)
+			if abs, err := filepath.Abs(split[0]); err == nil {
+				split[0] = abs
+			}
+			mount = strings.Join(split, ":")
+		}
+		d.Mounts = append(d.Mounts, mount)
+	}
+
+	if len(s.Env) > 0 && d.Env == nil {
+		d.Env = map[string]string{}
+	}
+
+	for key, value := range s.Env {
+		d.Env[key] = value
+	}
+}
+
+// enabledCapabilityTools returns IDs of enabled tools with the provided
+// capability. It exits when all of them are disabled.
+func enabledCapabilityTools(t *tools.Tools, cfg *Config, capability tools.Capability) []string {
+	var enabled, names []string
+
+	for _, id := range t.WithCapability(capability) {
+		tc := cfg.toolConfig(id)
+		if tc != nil && tc.Enabled {
+			enabled = append(enabled, id)
+		}
+		names = append(names, t.Get(id).Name())
+	}
+
+	if len(enabled) == 0 {
+		switch len(names) {
+		case 1:
+			fatalError(fmt.Sprintf("%s is disabled. Enable it first", names[0]))
+		case 2:
+			fatalError(fmt.Sprintf("both %s are disabled. Enable at least one of them first", joinNames(names)))
+		default:
+			fatalError(fmt.Sprintf("all of %s are disabled. Enable at least one of them first", joinNames(names)))
+		}
+	}
+
+	return enabled
+}
+
+// fatalUnavailableTools exits with an error listing the tools with the
+// provided IDs as not available.
+func fatalUnavailableTools(t *tools.Tools, ids []string) {
+	var names []string
+	for _, id := range ids {
+		names = append(names, t.Get(id).Name())
+	}
+
+	switch len(names) {
+	case 1:
+		fatalError(fmt.Sprintf("%s is not available", names[0]))
+	case 2:
+		fatalError(fmt.Sprintf("neither %s nor %s are available", names[0], names[1]))
+	default:
+		fatalError(fmt.Sprintf("none of %s are available", joinNames(names)))
+	}
+}
+
+// checkCapabilityTools checks which enabled tools with the provided capability
+// can be run and returns their IDs. It exits when none of them can.
+func checkCapabilityTools(t *tools.Tools, cfg *Config, capability tools.Capability) []string {
+	var result []string
+
+	enabled := enabledCapabilityTools(t, cfg, capability)
+
+	pullToolImages(t, enabled)
+
+	errs := make([]error, len(enabled))
+	versionErrs := make([]error, len(enabled))
+	t.Each(enabled, func(i int, id string, tool tools.Tooler) {
+		errs[i] = checkIfToolExists(t.Docker, tool)
+		if errs[i] == nil {
+			versionErrs[i] = checkToolVersion(cfg, id, tool)
+		}
+	})
+
+	for i, id := range enabled {
+		if errs[i] == nil {
+			result = append(result, id)
+		}
+	}
+
+	if len(result) == 0 {
+		fatalUnavailableTools(t, enabled)
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			printWarning(err)
+		}
+	}
+
+	fatalVersionErrors(versionErrs)
+
+	return result
 }
 
-func checkIfToolExists(docker tools.Tooler, tool tools.Tooler) error {
+// checkToolVersion checks if the tool version satisfies the constraint from
+// the configuration if there is one.
+func checkToolVersion(cfg *Config, id string, tool tools.Tooler) error {
+	if constraint, ok := cfg.Versions[id]; ok {
+		return tools.CheckVersion(tool, constraint)
+	}
+	return nil
+}
+
+// fatalVersionErrors prints all version errors and exits if there are any.
+func fatalVersionErrors(errs []error) {
+	var last error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if last != nil {
+			printError(last)
+		}
+		last = err
+	}
+
+	if last != nil {
+		fatalErrorWithCode(exitCodeVersion, last)
+	}
+}
+
+// errToolIssues is returned by the runTools functions when a tool has found
+// issues as opposed to a tool failure.
+var errToolIssues = errors.New("issues found")
+
+// runTools runs fn for each of the provided tools in parallel. The output
+// written by each of them is buffered and printed in the order of ids as soon
+// as all the previous ones have been printed along with the returned error.
+// Tools for which writes returns true, like formatters fixing files, are run
+// one at a time, so they never rewrite the same files at once.
+//
+// It returns exitCodeError if any of the tools has failed, exitCodeValidation
+// if any of them has found issues or 0 otherwise.
+func runTools(
+	t *tools.Tools,
+	ids []string,
+	writes func(id string) bool,
+	fn func(w io.Writer, id string, tool tools.Tooler) error,
+) int {
+	var writeMu sync.Mutex
+	bufs := make([]bytes.Buffer, len(ids))
+	errs := make([]error, len(ids))
+	done := make([]chan struct{}, len(ids))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	go t.Each(ids, func(i int, id string, tool tools.Tooler) {
+		defer close(done[i])
+		if writes != nil && writes(id) {
+			writeMu.Lock()
+			defer writeMu.Unlock()
+		}
+		errs[i] = fn(&bufs[i], id, tool)
+		if errs[i] != nil && !errors.Is(errs[i], errToolIssues) {
+			fprintToolError(&bufs[i], errs[i])
+		}
+	})
+
+	code := 0
+	printed := false
+	for i := range ids {
+		<-done[i]
+
+		switch {
+		case errs[i] == nil:
+		case errors.Is(errs[i], errToolIssues):
+			if code == 0 {
+				code = exitCodeValidation
+			}
+		default:
+			code = exitCodeError
+		}
+
+		if bufs[i].Len() == 0 {
+			continue
+		}
+
+		if printed {
+			fmt.Println()
+		}
+
+		_, _ = bufs[i].WriteTo(os.Stdout)
+		printed = true
+	}
+
+	return code
+}
+
+// fprintToolError prints the error. For tool failures with a multiline
+// standard error output, the whole output is printed first.
+func fprintToolError(w io.Writer, err error) {
+	var toolErr *tools.ToolError
+	if errors.As(err, &toolErr) && strings.Contains(toolErr.Stderr, "\n") {
+		fmt.Fprintln(w, toolErr.Stderr)
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, color.RedString("Error: %s", err))
+}
+
+var bundleOnce sync.Once
+
+// loadBundle loads images from the configured bundle. Only the first call does
+// the work, so it's safe to call for each missing image. Missing images are
+// pulled when it fails.
+func loadBundle(docker *tools.Docker) {
+	bundleOnce.Do(func() {
+		fmt.Printf("Loading Docker images from %s...\n", docker.Bundle)
+		if err := docker.LoadImages(docker.Bundle); err != nil {
+			printWarning("failed to load Docker images", err)
+		}
+	})
+}
+
+var pulledImages = map[string]bool{}
+var pulledImagesMu sync.Mutex
+
+// pullToolImage pulls the tool Docker image unless it's available, loading the
+// configured bundle first. Each image is only tried once, so tools sharing an
+// image don't pull it again.
+func pullToolImage(docker *tools.Docker, tool tools.Tooler) {
+	pulledImagesMu.Lock()
+	defer pulledImagesMu.Unlock()
+
+	image := tool.DockerImage()
+	if pulledImages[image] {
+		return
+	}
+	pulledImages[image] = true
+
+	if !tool.IsDockerImageAvailable() && len(docker.Bundle) > 0 {
+		loadBundle(docker)
+	}
+
+	if !tool.IsDockerImageAvailable() {
+		fmt.Printf("Pulling %s Docker image. It may take a few minutes...\n", image)
+		tool.PullDockerImage()
+	}
+}
+
+// pullToolImages pulls Docker images of the tools with the provided IDs which
+// are not available on the system one by one. It should be called before
+// checking the tools concurrently, so each image is pulled only once and the
+// output doesn't interleave.
+func pullToolImages(t *tools.Tools, ids []string) {
+	if !t.Docker.ExistsOnSystem() {
+		return
+	}
+
+	for _, id := range ids {
+		if tool := t.Get(id); !tool.ExistsOnSystem() {
+			pullToolImage(t.Docker, tool)
+		}
+	}
+}
+
+func checkIfToolExists(docker *tools.Docker, tool tools.Tooler) error {
 	if !tool.ExistsOnSystem() {
 		if !docker.ExistsOnSystem() {
 			return fmt.Errorf(
@@ -57,11 +490,10 @@ func checkIfToolExists(docker tools.Tooler, tool tools.Tooler) error {
 				docker.Name(),
 				docker.Name(),
 			)
-		} else if !tool.IsDockerImageAvailable() {
-			fmt.Printf("Pulling %s Docker image. It may take a few minutes...\n", tool.DockerImage())
-			tool.PullDockerImage()
 		}
 
+		pullToolImage(docker, tool)
+
 		if !tool.ExistsInDocker() {
 			return fmt.Errorf(
 				"%s is not available neither on the system nor through Docker",
@@ -76,7 +508,11 @@ func checkIfToolExists(docker tools.Tooler, tool tools.Tooler) error {
 }
 
 func printTitle(str string) {
-	fmt.Printf("[%s]\n\n", strings.ToUpper(str))
+	fprintTitle(os.Stdout, str)
+}
+
+func fprintTitle(w io.Writer, str string) {
+	fmt.Fprintf(w, "[%s]\n\n", strings.ToUpper(str))
 }
 
 func printNameValue(name string, value interface{}) {
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dstmodders/mod-cli/tools"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureStdout returns everything written to the standard output by fn.
+func captureStdout(t *testing.T, fn func()) string {
+	r, w, err := os.Pipe()
+	assert.Nil(t, err)
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	fn()
+	assert.Nil(t, w.Close())
+
+	return <-out
+}
+
+func newTestTools(t *testing.T, jobs int) *tools.Tools {
+	ts, err := tools.New()
+	assert.Nil(t, err)
+	ts.SetJobs(jobs)
+	return ts
+}
+
+func TestRunTools(t *testing.T) {
+	ts := newTestTools(t, 4)
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runTools(ts, []string{"a", "b", "c", "d"}, nil, func(w io.Writer, id string, _ tools.Tooler) error {
+			switch id {
+			case "a":
+				// finishes last but is still printed first
+				time.Sleep(20 * time.Millisecond)
+				fmt.Fprintln(w, "a")
+			case "b":
+				fmt.Fprintln(w, "b")
+				return errToolIssues
+			case "d":
+				return errors.New("failed")
+			}
+			return nil
+		})
+	})
+
+	assert.Equal(t, exitCodeError, code)
+	assert.Equal(t, "a\n\nb\n\nError: failed\n", out)
+}
+
+func TestRunTools_ExitCode(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name     string
+		errs     []error
+		expected int
+	}{
+		{"success", []error{nil, nil}, 0},
+		{"issues", []error{nil, errToolIssues}, exitCodeValidation},
+		{"failure", []error{failed, nil}, exitCodeError},
+		{"issues and failure", []error{errToolIssues, failed}, exitCodeError},
+		{"failure and issues", []error{failed, errToolIssues}, exitCodeError},
+		{"wrapped issues", []error{fmt.Errorf("lint: %w", errToolIssues)}, exitCodeValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := make([]string, len(tt.errs))
+			for i := range ids {
+				ids[i] = fmt.Sprint(i)
+			}
+
+			var code int
+			captureStdout(t, func() {
+				code = runTools(newTestTools(t, 2), ids, nil, func(_ io.Writer, id string, _ tools.Tooler) error {
+					for i := range ids {
+						if ids[i] == id {
+							return tt.errs[i]
+						}
+					}
+					return nil
+				})
+			})
+
+			assert.Equal(t, tt.expected, code)
+		})
+	}
+}
+
+func TestRunTools_Writes(t *testing.T) {
+	var mu sync.Mutex
+	running, writing, maxRunning, maxWriting := 0, 0, 0, 0
+
+	track := func(delta int, writes bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		running += delta
+		if writes {
+			writing += delta
+		}
+		if running > maxRunning {
+			maxRunning = running
+		}
+		if writing > maxWriting {
+			maxWriting = writing
+		}
+	}
+
+	writes := func(id string) bool {
+		return id[0] == 'w'
+	}
+
+	captureStdout(t, func() {
+		ids := []string{"w1", "w2", "r1", "w3", "r2"}
+		runTools(newTestTools(t, len(ids)), ids, writes, func(_ io.Writer, id string, _ tools.Tooler) error {
+			track(1, writes(id))
+			time.Sleep(20 * time.Millisecond)
+			track(-1, writes(id))
+			return nil
+		})
+	})
+
+	assert.Equal(t, 1, maxWriting, "tools writing files should run one at a time")
+	assert.Greater(t, maxRunning, 1, "other tools should still run in parallel")
+}
+
+func TestExitCodes(t *testing.T) {
+	// the values are documented, so they should never change
+	assert.Equal(t, 1, exitCodeError)
+	assert.Equal(t, 2, exitCodeValidation)
+	assert.Equal(t, 3, exitCodeDestExists)
+	assert.Equal(t, 4, exitCodeCanceled)
+	assert.Equal(t, 5, exitCodeVersion)
+}
+
+func TestFatalVersionErrors(t *testing.T) {
+	if os.Getenv("TEST_FATAL_VERSION_ERRORS") == "1" {
+		fatalVersionErrors([]error{nil})
+		fatalVersionErrors([]error{nil, errors.New("Test 1.0.0 doesn't satisfy the required version ^2")})
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatalVersionErrors$")
+	cmd.Env = append(os.Environ(), "TEST_FATAL_VERSION_ERRORS=1")
+	out, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	if assert.True(t, errors.As(err, &exitErr)) {
+		assert.Equal(t, exitCodeVersion, exitErr.ExitCode())
+	}
+	assert.Contains(t, string(out), "Error: Test 1.0.0 doesn't satisfy the required version ^2")
+}
+
+func TestApplyToolConfig(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Docker.ConfigDockerSettings = ConfigDockerSettings{
+		Args:    []string{"--global"},
+		Env:     map[string]string{"A": "global", "B": "global"},
+		Image:   "global:1.0",
+		Mounts:  []string{"/global:/global"},
+		Network: "none",
+		User:    "global",
+	}
+	cfg.Lint.Luacheck.DockerSettings = ConfigDockerSettings{
+		Args:   []string{"--tool"},
+		Env:    map[string]string{"B": "tool"},
+		Mounts: []string{"./cache:/cache"},
+		User:   "tool",
+	}
+	cfg.Tools = []ConfigCustomTool{
+		{ID: "registered", Name: "Registered", Cmd: "sh", Image: "registered:1.0"},
+		{
+			ConfigTool: ConfigTool{DockerSettings: ConfigDockerSettings{Image: "tool:1.0"}},
+			ID:         "configured",
+			Name:       "Configured",
+			Cmd:        "sh",
+			Image:      "registered:1.0",
+		},
+	}
+
+	ts := newTestTools(t, 1)
+	assert.Nil(t, addCustomTools(ts, cfg))
+	for _, id := range []string{"luacheck", "stylua", "registered", "configured"} {
+		applyToolConfig(ts, cfg, id)
+	}
+
+	cache, err := filepath.Abs("cache")
+	assert.Nil(t, err)
+
+	// tool settings replace or extend the global ones
+	d := ts.Get("luacheck").Dockerized()
+	assert.Equal(t, "global:1.0", d.Image)
+	assert.Equal(t, "tool", d.User)
+	assert.Equal(t, "none", d.Network)
+	assert.Equal(t, []string{"--global", "--tool"}, d.ExtraArgs)
+	assert.Equal(t, []string{"/global:/global", cache + ":/cache"}, d.Mounts)
+	assert.Equal(t, map[string]string{"A": "global", "B": "tool"}, d.Env)
+
+	// tools without their own settings only get the global ones
+	d = ts.Get("stylua").Dockerized()
+	assert.Equal(t, "global", d.User)
+	assert.Equal(t, []string{"--global"}, d.ExtraArgs)
+	assert.Equal(t, map[string]string{"A": "global", "B": "global"}, d.Env)
+
+	// images are taken from the global settings, the registration and the tool
+	// settings in that order
+	assert.Equal(t, "registered:1.0", ts.Get("registered").Dockerized().Image)
+	assert.Equal(t, "tool:1.0", ts.Get("configured").Dockerized().Image)
+}
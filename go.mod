@@ -8,6 +8,7 @@ require (
 	github.com/fbiville/markdown-table-formatter v0.0.0-20190820125121-3604ec932590
 	github.com/manifoldco/promptui v0.9.0
 	github.com/matishsiao/goInfo v0.0.0-20210923090445-da2e3fa8d45f
+	github.com/mattn/go-isatty v0.0.14
 	github.com/mattn/go-zglob v0.0.3
 	github.com/stretchr/testify v1.4.0
 	github.com/yuin/goldmark v1.4.4
@@ -19,5 +20,9 @@ require (
 require (
 	github.com/alecthomas/template v0.0.0-20190718012654-fb15b899a751 // indirect
 	github.com/alecthomas/units v0.0.0-20210927113745-59d0afb8317a // indirect
+	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/mattn/go-colorable v0.1.9 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.0.0-20210630005230-0f9fa26af87c // indirect
 )
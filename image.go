@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dstmodders/mod-cli/tools"
+)
+
+type Image struct {
+	cfg   *Config
+	tools *tools.Tools
+}
+
+func NewImage(cfg *Config) (*Image, error) {
+	t, err := newTools(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Image{
+		cfg:   cfg,
+		tools: t,
+	}, nil
+}
+
+func (i *Image) checkDocker() error {
+	if !i.tools.Docker.ExistsOnSystem() {
+		return fmt.Errorf("%s is not available on the system", i.tools.Docker.Name())
+	}
+	return nil
+}
+
+// pullImages pulls images of all tools which can be run in Docker unless they
+// are already available.
+func (i *Image) pullImages() error {
+	pulled := map[string]bool{}
+	for _, id := range i.tools.IDs() {
+		tool := i.tools.Get(id)
+		image := tool.DockerImage()
+
+		if r, ok := i.tools.Registration(id); !ok || !r.Dockerized || pulled[image] {
+			continue
+		}
+		pulled[image] = true
+
+		if !tool.IsDockerImageAvailable() {
+			fmt.Printf("Pulling %s Docker image. It may take a few minutes...\n", image)
+			if !tool.PullDockerImage() {
+				return fmt.Errorf("failed to pull %s Docker image", image)
+			}
+		}
+	}
+	return nil
+}
+
+func (i *Image) runExport(file string) error {
+	if err := i.checkDocker(); err != nil {
+		return err
+	}
+
+	images := i.tools.Images()
+	if len(images) == 0 {
+		return errors.New("no tools can be run in Docker")
+	}
+
+	if err := i.pullImages(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Saving %s to %s...\n", joinNames(images), file)
+	return i.tools.Docker.SaveImages(file, images...)
+}
+
+func (i *Image) runImport(file string) error {
+	if err := i.checkDocker(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Loading Docker images from %s...\n", file)
+	return i.tools.Docker.LoadImages(file)
+}
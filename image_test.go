@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dstmodders/mod-cli/tools"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDocker puts a docker script into PATH, which succeeds unless the command
+// is in FAIL, and returns a function to get the logged calls.
+func fakeDocker(t *testing.T) func() []string {
+	bin := t.TempDir()
+	log := filepath.Join(bin, "log")
+	writeFiles(t, bin, map[string]string{
+		"docker": `#!/bin/sh
+echo "$*" >> "` + log + `"
+[ "$1" = "$FAIL" ] && { echo "no space left on device"; exit 1; }
+exit 0
+`,
+	})
+	assert.Nil(t, os.Chmod(filepath.Join(bin, "docker"), 0o755))
+	t.Setenv("PATH", bin)
+	t.Setenv("FAIL", "")
+
+	return func() []string {
+		out, err := os.ReadFile(log)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		assert.Nil(t, err)
+		return strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	}
+}
+
+func newTestImage(t *testing.T) *Image {
+	return &Image{
+		cfg:   NewConfig(),
+		tools: newTestTools(t, 1),
+	}
+}
+
+func TestImage_runExport(t *testing.T) {
+	calls := fakeDocker(t)
+	i := newTestImage(t)
+
+	images := i.tools.Images()
+	assert.NotEmpty(t, images)
+
+	captureStdout(t, func() {
+		assert.Nil(t, i.runExport("images.tar"))
+	})
+
+	// each image is only checked once before saving all of them together
+	expected := make([]string, 0, len(images)+1)
+	for _, image := range images {
+		expected = append(expected, "image inspect "+image)
+	}
+	expected = append(expected, "save -o images.tar "+strings.Join(images, " "))
+
+	assert.Equal(t, expected, calls())
+}
+
+func TestImage_runExport_NoImages(t *testing.T) {
+	calls := fakeDocker(t)
+
+	docker, err := tools.NewDocker()
+	assert.Nil(t, err)
+
+	i := &Image{cfg: NewConfig(), tools: &tools.Tools{Docker: docker}}
+	assert.EqualError(t, i.runExport("images.tar"), "no tools can be run in Docker")
+	assert.Empty(t, calls())
+}
+
+func TestImage_runExport_Error(t *testing.T) {
+	fakeDocker(t)
+	t.Setenv("FAIL", "save")
+
+	captureStdout(t, func() {
+		err := newTestImage(t).runExport("images.tar")
+		assert.EqualError(t, err, "failed to save images: no space left on device")
+	})
+}
+
+func TestImage_runImport(t *testing.T) {
+	calls := fakeDocker(t)
+	file := filepath.Join(t.TempDir(), "images.tar")
+	i := newTestImage(t)
+
+	captureStdout(t, func() {
+		assert.NotNil(t, i.runImport(file), "missing file should fail")
+	})
+	assert.Empty(t, calls())
+
+	writeFiles(t, filepath.Dir(file), map[string]string{"images.tar": ""})
+
+	captureStdout(t, func() {
+		assert.Nil(t, i.runImport(file))
+	})
+	assert.Equal(t, []string{"load -i " + file}, calls())
+
+	t.Setenv("FAIL", "load")
+	captureStdout(t, func() {
+		assert.EqualError(t, i.runImport(file), "failed to load images: no space left on device")
+	})
+}
+
+func TestImage_checkDocker(t *testing.T) {
+	i := newTestImage(t)
+	t.Setenv("PATH", t.TempDir())
+	assert.EqualError(t, i.checkDocker(), "Docker is not available on the system")
+}
@@ -3,29 +3,26 @@ package main
 import (
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/dstmodders/mod-cli/tools"
 	"github.com/fatih/color"
 )
 
 type Lint struct {
-	Original       bool
-	canRunLuacheck bool
-	cfg            *Config
-	tools          *tools.Tools
+	Original bool
+	Paths    []string
+	canRun   []string
+	cfg      *Config
+	tools    *tools.Tools
 }
 
 func NewLint(cfg *Config) (*Lint, error) {
-	t, err := tools.New()
+	t, err := newTools(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	t.Luacheck.SetIgnore(cfg.Lint.Luacheck.Ignore)
-	if cfg.Lint.Luacheck.Docker {
-		t.Luacheck.SetRunInDocker(true)
-	}
-
 	return &Lint{
 		cfg:   cfg,
 		tools: t,
@@ -33,43 +30,17 @@ func NewLint(cfg *Config) (*Lint, error) {
 }
 
 func (l *Lint) checkTools() {
-	var errLuacheck error
-
-	//nolint:stylecheck
-	//goland:noinspection ALL
-	err := errors.New("Luacheck is not available")
-
-	if !l.cfg.Lint.Luacheck.Enabled {
-		fatalError("Luacheck is disabled. Enable it first")
-	}
-
-	if l.cfg.Lint.Luacheck.Enabled {
-		errLuacheck = checkIfToolExists(l.tools.Docker, l.tools.Luacheck)
-		if errLuacheck == nil {
-			l.canRunLuacheck = true
-			err = nil
-		}
-	}
-
-	if l.canRunLuacheck {
-		if errLuacheck != nil {
-			printWarning(errLuacheck)
-		}
-	}
-
-	if err != nil {
-		fatalError(err)
-	}
+	l.canRun = checkCapabilityTools(l.tools, l.cfg, tools.CapabilityLint)
 }
 
-func (l *Lint) printLint(lint tools.Lint) {
+func (l *Lint) printLint(w io.Writer, lint tools.Lint) {
 	if l.Original {
-		fmt.Println(lint.Stdout)
+		fmt.Fprintln(w, lint.Stdout)
 		return
 	}
 
 	if len(lint.Files) == 0 {
-		fmt.Println("No issues found")
+		fmt.Fprintln(w, "No issues found")
 		return
 	}
 
@@ -83,16 +54,18 @@ func (l *Lint) printLint(lint tools.Lint) {
 		}
 		issues = fmt.Sprintf("%d %s", len(file.Issues), issues)
 
-		fmt.Printf(
+		fmt.Fprintf(
+			w,
 			"%s %s %s\n",
 			color.YellowString("warning"),
 			file.Path,
 			color.YellowString(issues),
 		)
 
-		fmt.Println()
+		fmt.Fprintln(w)
 		for _, issue := range file.Issues {
-			fmt.Printf(
+			fmt.Fprintf(
+				w,
 				"    %s:%d:%d: %s\n",
 				issue.Name,
 				issue.StartLine,
@@ -102,26 +75,46 @@ func (l *Lint) printLint(lint tools.Lint) {
 		}
 
 		if i < (total - 1) {
-			fmt.Println()
+			fmt.Fprintln(w)
 		}
 	}
 }
 
-func (l *Lint) runLuacheck() error {
-	lint, err := l.tools.Luacheck.Lint()
-	if err != nil {
+func (l *Lint) runTool(w io.Writer, id string, tool tools.Tooler) error {
+	linter, ok := tool.(tools.Linter)
+	if !ok {
+		return fmt.Errorf("%s is not a linter", id)
+	}
+
+	lint, err := linter.Lint(l.Paths...)
+
+	var toolErr *tools.ToolError
+	if err != nil && !errors.As(err, &toolErr) {
 		return err
 	}
 
-	printTitle("Luacheck")
-	l.printLint(lint)
+	fprintTitle(w, linter.Name())
+
+	if toolErr != nil {
+		if l.Original && len(lint.Stdout) > 0 {
+			fmt.Fprintln(w, lint.Stdout)
+		}
+		return err
+	}
+
+	l.printLint(w, lint)
+
+	if lint.HasIssues() {
+		return errToolIssues
+	}
+
 	return nil
 }
 
 func (l *Lint) run() {
 	l.checkTools()
 
-	if l.canRunLuacheck && l.cfg.Lint.Luacheck.Enabled {
-		_ = l.runLuacheck()
+	if code := runTools(l.tools, l.canRun, nil, l.runTool); code != 0 {
+		exit(code)
 	}
 }
@@ -4,8 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"syscall"
 
+	"github.com/dstmodders/mod-cli/tools"
+	"github.com/dstmodders/mod-cli/workshop"
 	"gopkg.in/alecthomas/kingpin.v2"
 )
 
@@ -30,11 +35,14 @@ var (
 	changelogCmdList         = changelogCmd.Flag("list", "Show list of releases without changes.").Bool()
 	changelogCmdListVersions = changelogCmd.Flag("list-versions", "Show list of versions.").Bool()
 
-	doctorCmd = app.Command("doctor", "Check health of this CLI app.").Hidden()
+	doctorCmd     = app.Command("doctor", "Check health of this CLI app.").Hidden()
+	doctorCmdJobs = doctorCmd.Flag("jobs", "Number of tools to run in parallel. Defaults to the number of CPUs.").Short('j').PlaceHolder("N").String()
 
 	formatCmd         = app.Command("format", "Code formatting tools: Prettier and StyLua.")
+	formatCmdPaths    = formatCmd.Arg("paths", "Files, directories or globs to format. Defaults to all files.").Strings()
 	formatCmdDocker   = formatCmd.Flag("docker", "Run through Docker.").Short('d').Bool()
 	formatCmdFix      = formatCmd.Flag("fix", "Fix issues automatically. Beware!").Short('f').Bool()
+	formatCmdJobs     = formatCmd.Flag("jobs", "Number of tools to run in parallel. Defaults to the number of CPUs.").Short('j').PlaceHolder("N").String()
 	formatCmdPrettier = formatCmd.Flag("prettier", "Run Prettier.").Short('p').Bool()
 	formatCmdStyLua   = formatCmd.Flag("stylua", "Run StyLua.").Short('s').Bool()
 
@@ -51,17 +59,62 @@ var (
 	infoCmdOther                 = infoCmd.Flag("other", "Show other fields.").Short('o').Bool()
 
 	lintCmd         = app.Command("lint", "Code linting tools: Luacheck.")
+	lintCmdPaths    = lintCmd.Arg("paths", "Files, directories or globs to lint. Defaults to all files.").Strings()
 	lintCmdDocker   = lintCmd.Flag("docker", "Run through Docker.").Short('d').Bool()
+	lintCmdJobs     = lintCmd.Flag("jobs", "Number of tools to run in parallel. Defaults to the number of CPUs.").Short('j').PlaceHolder("N").String()
 	lintCmdLuacheck = lintCmd.Flag("luacheck", "Run Luacheck.").Short('l').Bool()
 	lintCmdOriginal = lintCmd.Flag("original", "Show original output instead.").Short('o').Bool()
 
 	testCmd = app.Command("test", "Testing tools: Busted.")
 
-	workshopCmd     = app.Command("workshop", "Steam Workshop tools.")
-	workshopCmdPath = workshopCmd.Arg("path", "Path to mod directory.").Default(".").ExistingDir()
-	workshopCmdList = workshopCmd.Flag("list", "Show only files that are going to be included.").Short('l').Bool()
-	workshopCmdName = workshopCmd.Flag("name", "Name of destination directory/archive.").Default("workshop").Short('n').String()
-	workshopCmdZip  = workshopCmd.Flag("zip", "Create a ZIP archive instead.").Short('z').Bool()
+	toolsCmd                = app.Command("tools", "Tools management.")
+	toolsImageCmd           = toolsCmd.Command("image", "Docker images of tools for offline use.")
+	toolsImageExportCmd     = toolsImageCmd.Command("export", "Save Docker images of all tools into a tarball.")
+	toolsImageExportCmdFile = toolsImageExportCmd.Arg("file", "Path to tarball.").Required().String()
+	toolsImageImportCmd     = toolsImageCmd.Command("import", "Load Docker images of tools from a tarball.")
+	toolsImageImportCmdFile = toolsImageImportCmd.Arg("file", "Path to tarball.").Required().ExistingFile()
+
+	workshopCmd         = app.Command("workshop", "Steam Workshop tools.")
+	workshopCmdJobs     = workshopCmd.Flag("jobs", "Number of files to process in parallel. Defaults to the number of CPUs.").Short('j').PlaceHolder("N").String()
+	workshopCmdMinify   = workshopCmd.Flag("minify", "Strip comments from packaged Lua files.").Short('m').Bool()
+	workshopCmdName     = workshopCmd.Flag("name", "Name of destination directory/archive.").Default("workshop").Short('n').String()
+	workshopCmdOut      = workshopCmd.Flag("out", "Path to directory to put destination directory/archive in. Defaults to mod directory.").Short('o').String()
+	workshopCmdValidate = workshopCmd.Flag("validate", "Validate files before copying or zipping. Use --no-validate to skip.").Default("true").Bool()
+
+	workshopCmdForce       = workshopCmd.Flag("force", "Override destination without asking. Same as --yes.").Bool()
+	workshopCmdNoOverwrite = workshopCmd.Flag("no-overwrite", "Fail if destination already exists instead of asking.").Bool()
+	workshopCmdYes         = workshopCmd.Flag("yes", "Override destination without asking.").Short('y').Bool()
+
+	workshopBuildCmd             = workshopCmd.Command("build", "Prepare a mod directory or archive.").Default()
+	workshopBuildCmdPath         = workshopBuildCmd.Arg("path", "Path to mod directory.").Default(".").ExistingDir()
+	workshopBuildCmdAnalyze      = workshopBuildCmd.Flag("analyze", "Show referenced but excluded and included but unreferenced files.").Short('a').Bool()
+	workshopBuildCmdCompression  = workshopBuildCmd.Flag("compression", "Archive compression level: from 0 (none) to 9 (best).").PlaceHolder("LEVEL").String()
+	workshopBuildCmdFormat       = workshopBuildCmd.Flag("format", "Output format: dir, zip or tar.gz.").Short('f').Enum(workshop.Formats...)
+	workshopBuildCmdList         = workshopBuildCmd.Flag("list", "Show only files that are going to be included.").Short('l').Bool()
+	workshopBuildCmdReproducible = workshopBuildCmd.Flag("reproducible", "Create a reproducible archive. Respects SOURCE_DATE_EPOCH.").Short('r').Bool()
+	workshopBuildCmdSize         = workshopBuildCmd.Flag("size", "Show size breakdown by directory and extension. Fails if over workshop.max_size.").Short('s').Bool()
+	workshopBuildCmdZip          = workshopBuildCmd.Flag("zip", "Create a ZIP archive instead. Same as --format=zip.").Short('z').Bool()
+
+	workshopInspectCmd        = workshopCmd.Command("inspect", "Show mod info, files and differences from the source of a built or downloaded package.")
+	workshopInspectCmdPackage = workshopInspectCmd.Arg("package", "Path to package: directory, ZIP archive or gzipped tarball.").Required().ExistingFileOrDir()
+	workshopInspectCmdPath    = workshopInspectCmd.Arg("path", "Path to mod directory to compare with.").Default(".").ExistingDir()
+	workshopInspectCmdDiff    = workshopInspectCmd.Flag("diff", "Compare with the mod directory. Use --no-diff to skip.").Default("true").Bool()
+
+	workshopInstallCmd        = workshopCmd.Command("install", "Prepare a mod directory and install it into the game mods directory.")
+	workshopInstallCmdPath    = workshopInstallCmd.Arg("path", "Path to mod directory.").Default(".").ExistingDir()
+	workshopInstallCmdFolder  = workshopInstallCmd.Flag("folder", "Mod folder name. Defaults to folder_name from modinfo or workspace-<name>.").String()
+	workshopInstallCmdSymlink = workshopInstallCmd.Flag("symlink", "Link the mod folder to the destination directory instead of copying.").Short('s').Bool()
+	workshopInstallCmdTarget  = workshopInstallCmd.Flag("target", "Path to the game mods directory.").Short('t').String()
+
+	workshopPublishCmd           = workshopCmd.Command("publish", "Prepare a mod directory and publish it using SteamCMD.")
+	workshopPublishCmdPath       = workshopPublishCmd.Arg("path", "Path to mod directory.").Default(".").ExistingDir()
+	workshopPublishCmdChangeNote = workshopPublishCmd.Flag("change-note", "Change note. Defaults to the current version release from changelog.").String()
+	workshopPublishCmdDryRun     = workshopPublishCmd.Flag("dry-run", "Only generate a VDF file without running SteamCMD.").Bool()
+	workshopPublishCmdID         = workshopPublishCmd.Flag("id", "Published file ID of an existing item to update.").String()
+	workshopPublishCmdPreview    = workshopPublishCmd.Flag("preview", "Path to preview image.").String()
+	workshopPublishCmdSteamCMD   = workshopPublishCmd.Flag("steamcmd", "Path to SteamCMD binary.").String()
+	workshopPublishCmdUsername   = workshopPublishCmd.Flag("username", "Steam username.").Short('u').String()
+	workshopPublishCmdVisibility = workshopPublishCmd.Flag("visibility", "Visibility: 0 (public), 1 (friends only), 2 (private) or 3 (unlisted).").PlaceHolder("VISIBILITY").String()
 )
 
 func enableConfigBool(value *bool, docker *bool) {
@@ -70,7 +123,23 @@ func enableConfigBool(value *bool, docker *bool) {
 	}
 }
 
-func loadConfig() {
+func setConfigString(value *string, flag *string) {
+	if len(*flag) > 0 {
+		*value = *flag
+	}
+}
+
+func setConfigJobs(value *int, flag *string) {
+	if len(*flag) > 0 {
+		jobs, err := strconv.Atoi(*flag)
+		if err != nil || jobs < 1 {
+			fatalError("failed to parse arguments", fmt.Errorf("invalid number of jobs: %s", *flag))
+		}
+		*value = jobs
+	}
+}
+
+func loadConfigFile() {
 	if len(*appConfig) > 0 && *appConfig != ".modcli" {
 		errMsg := "failed to load config"
 		stat, err := os.Stat(*appConfig)
@@ -102,8 +171,9 @@ func loadConfig() {
 			fatalError(errMsg, err)
 		}
 	}
+}
 
-	// format
+func setConfigFormat() {
 	enableConfigBool(&cfg.Format.Prettier.Docker, formatCmdDocker)
 	enableConfigBool(&cfg.Format.StyLua.Docker, formatCmdDocker)
 
@@ -112,12 +182,86 @@ func loadConfig() {
 
 	enableConfigBool(&cfg.Format.Prettier.Fix, formatCmdFix)
 	enableConfigBool(&cfg.Format.StyLua.Fix, formatCmdFix)
+}
 
-	// lint
+func setConfigLint() {
 	enableConfigBool(&cfg.Lint.Luacheck.Docker, lintCmdDocker)
 	enableConfigBool(&cfg.Lint.Luacheck.Enabled, lintCmdLuacheck)
 }
 
+func setConfigTools() {
+	for i := range cfg.Tools {
+		tool := &cfg.Tools[i]
+		if tool.Capabilities.Has(tools.CapabilityFormat) {
+			enableConfigBool(&tool.Docker, formatCmdDocker)
+			enableConfigBool(&tool.Fix, formatCmdFix)
+		}
+		if tool.Capabilities.Has(tools.CapabilityLint) {
+			enableConfigBool(&tool.Docker, lintCmdDocker)
+		}
+	}
+}
+
+func setConfigWorkshop() {
+	enableConfigBool(&cfg.Workshop.Minify.Enabled, workshopCmdMinify)
+	enableConfigBool(&cfg.Workshop.Reproducible, workshopBuildCmdReproducible)
+
+	if !*workshopCmdValidate {
+		cfg.Workshop.Validate = false
+	}
+
+	if len(*workshopBuildCmdCompression) > 0 {
+		level, err := strconv.Atoi(*workshopBuildCmdCompression)
+		if err != nil {
+			fatalError("failed to parse arguments", fmt.Errorf("invalid compression level: %s", *workshopBuildCmdCompression))
+		}
+		cfg.Workshop.Compression = level
+	}
+
+	setConfigJobs(&cfg.Workshop.Jobs, workshopCmdJobs)
+
+	setConfigString(&cfg.Workshop.Format, workshopBuildCmdFormat)
+	setConfigString(&cfg.Workshop.Out, workshopCmdOut)
+
+	if *workshopBuildCmdZip {
+		cfg.Workshop.Format = workshop.FormatZip.String()
+	}
+
+	setConfigString(&cfg.Workshop.Install.Folder, workshopInstallCmdFolder)
+	setConfigString(&cfg.Workshop.Install.Target, workshopInstallCmdTarget)
+	enableConfigBool(&cfg.Workshop.Install.Symlink, workshopInstallCmdSymlink)
+
+	setConfigString(&cfg.Workshop.Publish.ID, workshopPublishCmdID)
+	setConfigString(&cfg.Workshop.Publish.Preview, workshopPublishCmdPreview)
+	setConfigString(&cfg.Workshop.Publish.SteamCMD, workshopPublishCmdSteamCMD)
+	setConfigString(&cfg.Workshop.Publish.Username, workshopPublishCmdUsername)
+
+	if len(*workshopPublishCmdVisibility) > 0 {
+		visibility, err := strconv.Atoi(*workshopPublishCmdVisibility)
+		if err != nil || visibility < 0 || visibility > 3 {
+			fatalError("failed to parse arguments", fmt.Errorf("invalid visibility: %s", *workshopPublishCmdVisibility))
+		}
+		cfg.Workshop.Publish.Visibility = visibility
+	}
+}
+
+func loadConfig() {
+	loadConfigFile()
+
+	// jobs
+	setConfigJobs(&cfg.Jobs, doctorCmdJobs)
+	setConfigJobs(&cfg.Jobs, formatCmdJobs)
+	setConfigJobs(&cfg.Jobs, lintCmdJobs)
+
+	// docker
+	cfg.Docker.resolve()
+
+	setConfigFormat()
+	setConfigLint()
+	setConfigTools()
+	setConfigWorkshop()
+}
+
 func runChangelog() {
 	c := NewChangelog()
 	c.Count = *changelogCmdCount
@@ -136,6 +280,7 @@ func runDoctor() {
 	if err := d.run(); err != nil {
 		fatalError("failed to run doctor command", err)
 	}
+	fatalVersionErrors(d.versionErrs)
 }
 
 func runFormat() {
@@ -143,6 +288,7 @@ func runFormat() {
 	if err != nil {
 		fatalError(err.Error())
 	}
+	f.Paths = *formatCmdPaths
 	f.run()
 }
 
@@ -166,6 +312,7 @@ func runInfo() {
 func runLint() {
 	l, err := NewLint(cfg)
 	l.Original = *lintCmdOriginal
+	l.Paths = *lintCmdPaths
 
 	if err != nil {
 		fatalError(err.Error())
@@ -184,15 +331,78 @@ func runTest() {
 	l.run()
 }
 
+func runToolsImageExport() {
+	i, err := NewImage(cfg)
+	if err != nil {
+		fatalError(err.Error())
+	}
+
+	if err := i.runExport(*toolsImageExportCmdFile); err != nil {
+		fatalError("failed to run tools image export command", err)
+	}
+}
+
+func runToolsImageImport() {
+	i, err := NewImage(cfg)
+	if err != nil {
+		fatalError(err.Error())
+	}
+
+	if err := i.runImport(*toolsImageImportCmdFile); err != nil {
+		fatalError("failed to run tools image import command", err)
+	}
+}
+
 func runWorkshop() {
+	w := NewWorkshop(cfg)
+	w.analyze = *workshopBuildCmdAnalyze
+	w.destName = *workshopCmdName
+	w.list = *workshopBuildCmdList
+	w.noOverwrite = *workshopCmdNoOverwrite
+	w.path = *workshopBuildCmdPath
+	w.size = *workshopBuildCmdSize
+	w.yes = *workshopCmdYes || *workshopCmdForce
+
+	if err := w.run(); err != nil {
+		fatalErrorWithCode(workshopExitCode(err), "failed to run workshop command", err)
+	}
+}
+
+func runWorkshopInspect() {
+	w := NewWorkshop(cfg)
+	w.destName = *workshopCmdName
+	w.path = *workshopInspectCmdPath
+
+	if err := w.inspect(*workshopInspectCmdPackage, *workshopInspectCmdDiff); err != nil {
+		fatalError("failed to run workshop inspect command", err)
+	}
+}
+
+func runWorkshopInstall() {
 	w := NewWorkshop(cfg)
 	w.destName = *workshopCmdName
-	w.list = *workshopCmdList
-	w.path = *workshopCmdPath
-	w.zip = *workshopCmdZip
+	w.install = true
+	w.noOverwrite = *workshopCmdNoOverwrite
+	w.path = *workshopInstallCmdPath
+	w.yes = *workshopCmdYes || *workshopCmdForce
 
 	if err := w.run(); err != nil {
-		fatalError("failed to run workshop command", err)
+		fatalErrorWithCode(workshopExitCode(err), "failed to run workshop install command", err)
+	}
+}
+
+func runWorkshopPublish() {
+	w := NewWorkshop(cfg)
+	w.changeNote = *workshopPublishCmdChangeNote
+	w.destName = *workshopCmdName
+	w.dryRun = *workshopPublishCmdDryRun
+	w.noOverwrite = *workshopCmdNoOverwrite
+	w.path = *workshopPublishCmdPath
+	w.publish = true
+	w.yes = *workshopCmdYes || *workshopCmdForce
+
+	if err := w.run(); err != nil {
+		fatalErrorWithCode(workshopExitCode(err), "failed to run workshop publish command", err)
 	}
 }
 
@@ -210,6 +420,16 @@ func main() {
 	// config
 	loadConfig()
 
+	// cleanup
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		runExitHooks()
+		os.Exit(exitCodeCanceled)
+	}()
+	defer runExitHooks()
+
 	// commands
 	switch kingpin.MustParse(command, err) {
 	case changelogCmd.FullCommand():
@@ -224,7 +444,17 @@ func main() {
 		runLint()
 	case testCmd.FullCommand():
 		runTest()
-	case workshopCmd.FullCommand():
+	case toolsImageExportCmd.FullCommand():
+		runToolsImageExport()
+	case toolsImageImportCmd.FullCommand():
+		runToolsImageImport()
+	case workshopBuildCmd.FullCommand():
 		runWorkshop()
+	case workshopInspectCmd.FullCommand():
+		runWorkshopInspect()
+	case workshopInstallCmd.FullCommand():
+		runWorkshopInstall()
+	case workshopPublishCmd.FullCommand():
+		runWorkshopPublish()
 	}
 }
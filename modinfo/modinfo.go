@@ -12,6 +12,7 @@ import (
 type Controller interface {
 	FieldByName(string) *Field
 	Load(string) error
+	LoadString(string) error
 }
 
 // ModInfo represents modinfo.lua data.
@@ -302,13 +303,11 @@ func (m *ModInfo) setValues() error {
 	return nil
 }
 
-// Load loads modinfo.lua files from the provided path and sets all the
-// supported values.
-func (m *ModInfo) Load(path string) error {
+func (m *ModInfo) load(do func(*lua.LState) error) error {
 	m.lState = lua.NewState()
 	defer m.lState.Close()
 
-	if err := m.lState.DoFile(path); err != nil {
+	if err := do(m.lState); err != nil {
 		return err
 	}
 
@@ -319,6 +318,22 @@ func (m *ModInfo) Load(path string) error {
 	return nil
 }
 
+// Load loads modinfo.lua files from the provided path and sets all the
+// supported values.
+func (m *ModInfo) Load(path string) error {
+	return m.load(func(l *lua.LState) error {
+		return l.DoFile(path)
+	})
+}
+
+// LoadString loads modinfo.lua from the provided source and sets all the
+// supported values.
+func (m *ModInfo) LoadString(src string) error {
+	return m.load(func(l *lua.LState) error {
+		return l.DoString(src)
+	})
+}
+
 // FieldByName returns a single Field based on its original global name.
 func (m *ModInfo) FieldByName(name string) (*Field, error) {
 	if val, ok := m.General[name]; ok {
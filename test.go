@@ -2,36 +2,49 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"os"
 
 	"github.com/dstmodders/mod-cli/tools"
 )
 
 type Test struct {
+	busted       tools.Tester
 	canRunBusted bool
 	cfg          *Config
 	tools        *tools.Tools
 }
 
 func NewTest(cfg *Config) (*Test, error) {
-	t, err := tools.New()
+	t, err := newTools(cfg)
 	if err != nil {
 		return nil, err
 	}
 
+	busted, ok := t.Get("busted").(tools.Tester)
+	if !ok {
+		return nil, errors.New("busted is not registered")
+	}
+
 	return &Test{
-		cfg:   cfg,
-		tools: t,
+		busted: busted,
+		cfg:    cfg,
+		tools:  t,
 	}, nil
 }
 
 func (t *Test) checkTools() {
 	var errBusted error
 
+	if !t.cfg.Test.Busted.Enabled {
+		fatalError(fmt.Sprintf("%s is disabled. Enable it first", t.busted.Name()))
+	}
+
 	//nolint:stylecheck
 	//goland:noinspection ALL
 	err := errors.New("Busted is not available")
 
-	errBusted = checkIfToolExists(t.tools.Docker, t.tools.Busted)
+	errBusted = checkIfToolExists(t.tools.Docker, t.busted)
 	if errBusted == nil {
 		t.canRunBusted = true
 		err = nil
@@ -46,17 +59,33 @@ func (t *Test) checkTools() {
 	if err != nil {
 		fatalError(err)
 	}
+
+	fatalVersionErrors([]error{checkToolVersion(t.cfg, "busted", t.busted)})
 }
 
 func (t *Test) runBusted() error {
-	_, err := t.tools.Busted.Test()
+	result, err := t.busted.Test()
 	if err != nil {
 		return err
 	}
+
+	if result.ExitCode != 0 {
+		return errToolIssues
+	}
+
 	return nil
 }
 
 func (t *Test) run() {
 	t.checkTools()
-	_ = t.runBusted()
+
+	err := t.runBusted()
+	switch {
+	case err == nil:
+	case errors.Is(err, errToolIssues):
+		exit(exitCodeValidation)
+	default:
+		fprintToolError(os.Stdout, err)
+		exit(exitCodeError)
+	}
 }
@@ -0,0 +1,70 @@
+package tools
+
+import "strings"
+
+// MaxArgsLength holds the maximum total length of file arguments passed to a
+// single tool run. Longer file lists are split into batches run one by one to
+// stay below the command line length limits like ARG_MAX on Unix or 32767
+// characters on Windows, including the ones added by "docker run".
+var MaxArgsLength = 30000
+
+// batches splits files into batches with the total length of each below
+// MaxArgsLength. Each batch has at least one file.
+func batches(files []string) (result [][]string) {
+	var batch []string
+	var length int
+
+	for _, file := range files {
+		if len(batch) > 0 && length+len(file)+1 > MaxArgsLength {
+			result = append(result, batch)
+			batch = nil
+			length = 0
+		}
+
+		batch = append(batch, file)
+		length += len(file) + 1
+	}
+
+	if len(batch) > 0 {
+		result = append(result, batch)
+	}
+
+	return result
+}
+
+// eachBatch calls fn for each batch of files. It stops on the first error.
+func eachBatch(files []string, fn func(batch []string) error) error {
+	for _, batch := range batches(files) {
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge merges a result of another run of the same tool. The highest exit code
+// is kept and the standard error outputs are joined.
+func (r *RunResult) merge(other RunResult) {
+	if other.ExitCode > r.ExitCode {
+		r.ExitCode = other.ExitCode
+	}
+
+	if len(other.Stderr) > 0 {
+		r.Stderr = strings.TrimSpace(r.Stderr + "\n" + other.Stderr)
+	}
+}
+
+// merge merges a result of another run of the same tool on different files.
+func (l *Lint) merge(other Lint) {
+	l.Files = append(l.Files, other.Files...)
+	if len(other.Stdout) > 0 {
+		l.Stdout = strings.TrimSpace(l.Stdout + "\n" + other.Stdout)
+	}
+	l.RunResult.merge(other.RunResult)
+}
+
+// merge merges a result of another run of the same tool on different files.
+func (f *Format) merge(other Format) {
+	f.Files = append(f.Files, other.Files...)
+	f.RunResult.merge(other.RunResult)
+}
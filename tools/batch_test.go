@@ -0,0 +1,236 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setMaxArgsLength sets MaxArgsLength for the rest of the test.
+func setMaxArgsLength(t *testing.T, length int) {
+	prev := MaxArgsLength
+	MaxArgsLength = length
+	t.Cleanup(func() {
+		MaxArgsLength = prev
+	})
+}
+
+func TestBatches(t *testing.T) {
+	setMaxArgsLength(t, 10)
+
+	tests := []struct {
+		name     string
+		files    []string
+		expected [][]string
+	}{
+		{"no files", nil, nil},
+		{"single batch", []string{"a", "b", "c"}, [][]string{{"a", "b", "c"}}},
+		{
+			// each file takes its length plus a separator
+			"exactly at the limit",
+			[]string{"abcd", "efgh"},
+			[][]string{{"abcd", "efgh"}},
+		},
+		{
+			"above the limit",
+			[]string{"abcd", "efgh", "i"},
+			[][]string{{"abcd", "efgh"}, {"i"}},
+		},
+		{
+			"multiple batches",
+			[]string{"abc", "def", "ghi", "jkl", "mno"},
+			[][]string{{"abc", "def"}, {"ghi", "jkl"}, {"mno"}},
+		},
+		{
+			"file longer than the limit",
+			[]string{"a", strings.Repeat("b", 20), "c"},
+			[][]string{{"a"}, {strings.Repeat("b", 20)}, {"c"}},
+		},
+		{
+			"only a file longer than the limit",
+			[]string{strings.Repeat("b", 20)},
+			[][]string{{strings.Repeat("b", 20)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, batches(tt.files))
+		})
+	}
+}
+
+func TestEachBatch(t *testing.T) {
+	setMaxArgsLength(t, 4)
+
+	var result [][]string
+	err := eachBatch([]string{"a", "b", "c", "d", "e"}, func(batch []string) error {
+		result = append(result, batch)
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, result)
+}
+
+func TestEachBatch_Error(t *testing.T) {
+	setMaxArgsLength(t, 4)
+
+	expected := errors.New("failed")
+	calls := 0
+	err := eachBatch([]string{"a", "b", "c", "d", "e"}, func(batch []string) error {
+		calls++
+		return expected
+	})
+
+	assert.Equal(t, expected, err)
+	assert.Equal(t, 1, calls, "should stop on the first error")
+}
+
+func TestRunResult_merge(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []RunResult
+		expected RunResult
+	}{
+		{
+			"success",
+			[]RunResult{{}, {}},
+			RunResult{},
+		},
+		{
+			"highest exit code",
+			[]RunResult{{ExitCode: 1}, {ExitCode: 2}, {ExitCode: 0}},
+			RunResult{ExitCode: 2},
+		},
+		{
+			"joined stderr",
+			[]RunResult{{Stderr: "first"}, {}, {Stderr: "second\n"}},
+			RunResult{Stderr: "first\nsecond"},
+		},
+		{
+			"stderr of later runs only",
+			[]RunResult{{}, {ExitCode: 1, Stderr: "error"}},
+			RunResult{ExitCode: 1, Stderr: "error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.results[0]
+			for _, other := range tt.results[1:] {
+				result.merge(other)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestLint_merge(t *testing.T) {
+	result := Lint{
+		Files:     []LintFile{{Path: "a.lua"}},
+		Stdout:    "a.lua: issue",
+		RunResult: RunResult{ExitCode: 1},
+	}
+
+	result.merge(Lint{})
+	result.merge(Lint{
+		Files:     []LintFile{{Path: "b.lua"}, {Path: "c.lua"}},
+		Stdout:    "b.lua: issue\nc.lua: issue\n",
+		RunResult: RunResult{ExitCode: 2, Stderr: "warning"},
+	})
+
+	assert.Equal(t, Lint{
+		Files:     []LintFile{{Path: "a.lua"}, {Path: "b.lua"}, {Path: "c.lua"}},
+		Stdout:    "a.lua: issue\nb.lua: issue\nc.lua: issue",
+		RunResult: RunResult{ExitCode: 2, Stderr: "warning"},
+	}, result)
+}
+
+func TestFormat_merge(t *testing.T) {
+	result := Format{
+		Files: []FormatFile{{Path: "a.lua", State: FileStateWarning}},
+	}
+
+	result.merge(Format{
+		Files:     []FormatFile{{Path: "b.lua", State: FileStateSuccess}},
+		RunResult: RunResult{ExitCode: 1, Stderr: "error"},
+	})
+
+	assert.Equal(t, Format{
+		Files: []FormatFile{
+			{Path: "a.lua", State: FileStateWarning},
+			{Path: "b.lua", State: FileStateSuccess},
+		},
+		RunResult: RunResult{ExitCode: 1, Stderr: "error"},
+	}, result)
+}
+
+func TestCustom_Lint_Batches(t *testing.T) {
+	setMaxArgsLength(t, 12)
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.lua", "b.lua", "c.lua"} {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte("return"), 0o600))
+	}
+
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(dir))
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	c, err := NewCustom("Test", "sh")
+	assert.Nil(t, err)
+
+	// reports each file and exits with the number of files in the batch
+	c.Args = []string{"-c", `for f; do echo "$f:1:2: issue"; done; echo "batch of $#" >&2; exit $#`, "sh"}
+	c.Output = regexp.MustCompile(`^(?P<file>[^:]+):(?P<line>\d+):(?P<column>\d+): (?P<message>.*)$`)
+
+	result, err := c.Lint("a.lua", "b.lua", "c.lua")
+	assert.Nil(t, err)
+	assert.Len(t, result.Files, 3)
+	assert.Equal(t, "a.lua:1:2: issue\nb.lua:1:2: issue\nc.lua:1:2: issue", result.Stdout)
+	assert.Equal(t, 2, result.ExitCode)
+	assert.Equal(t, "batch of 2\nbatch of 1", result.Stderr)
+}
+
+func TestCustom_Fix_Batches(t *testing.T) {
+	// long enough to be split into batches using the default MaxArgsLength
+	names := make([]string, 1000)
+	for i := range names {
+		names[i] = fmt.Sprintf("scripts/components/%04d_%s.lua", i, strings.Repeat("x", 20))
+	}
+	assert.Greater(t, len(strings.Join(names, " ")), MaxArgsLength)
+
+	chdirFiles(t, names...)
+	log := filepath.Join(t.TempDir(), "log")
+
+	c, err := NewCustom("Test", "sh")
+	assert.Nil(t, err)
+	c.FixArgs = []string{"-c", `printf '%s\n' "$@" >> "` + log + `"; echo "---" >> "` + log + `"`, "sh"}
+
+	_, err = c.Fix(names...)
+	assert.Nil(t, err)
+
+	out, err := os.ReadFile(log)
+	assert.Nil(t, err)
+
+	var ran []string
+	calls := strings.Split(strings.TrimSuffix(string(out), "---\n"), "---\n")
+	for _, call := range calls {
+		batch := strings.Split(strings.TrimSuffix(call, "\n"), "\n")
+		assert.LessOrEqual(t, len(strings.Join(batch, " ")), MaxArgsLength)
+		ran = append(ran, batch...)
+	}
+
+	assert.Greater(t, len(calls), 1, "files should be split into batches")
+	assert.Equal(t, names, ran, "each file should run exactly once")
+}
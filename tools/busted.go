@@ -4,12 +4,26 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 )
 
 // Busted represents a Busted tool.
 type Busted struct {
 	Tool
+	stdout io.Writer
+}
+
+func init() {
+	Register(Registration{
+		ID:           "busted",
+		Capabilities: CapabilityTest,
+		Dockerized:   true,
+		New: func() (Tooler, error) {
+			return NewBusted()
+		},
+	})
 }
 
 // NewBusted creates a new Busted instance.
@@ -19,7 +33,8 @@ func NewBusted() (*Busted, error) {
 		return nil, err
 	}
 	return &Busted{
-		Tool: *tool,
+		Tool:   *tool,
+		stdout: os.Stdout,
 	}, nil
 }
 
@@ -44,24 +59,35 @@ func (b *Busted) LoadVersion() (string, error) {
 	return ver, nil
 }
 
-// Test runs tests.
+// Test runs tests printing the output as is. Busted exits with the number of
+// failed tests, so a non-zero exit code is only treated as a failure when
+// nothing has been printed. Otherwise, the standard error output is printed
+// after the output, so errors like a crash in the middle of a run aren't lost.
 func (b *Busted) Test() (result Lint, err error) {
-	cmd := b.ExecCommand(".")
-	stdout, _ := cmd.StdoutPipe()
+	var printed bool
 
-	if err := cmd.Start(); err != nil {
+	run, err := runCommand(b.ExecCommand("."), func(r io.Reader) {
+		scanner := bufio.NewScanner(r)
+		scanner.Split(bufio.ScanRunes)
+		for scanner.Scan() {
+			r := scanner.Text()
+			r = ansiRegex.ReplaceAllString(r, "")
+			fmt.Fprint(b.stdout, r)
+			printed = true
+		}
+	})
+	if err != nil {
 		return result, err
 	}
 
-	scanner := bufio.NewScanner(stdout)
-	scanner.Split(bufio.ScanRunes)
-	for scanner.Scan() {
-		r := scanner.Text()
-		r = ansiRegex.ReplaceAllString(r, "")
-		fmt.Print(r)
+	result.RunResult = run
+	if err := run.check(b.Name(), printed); err != nil {
+		return result, err
 	}
 
-	_ = cmd.Wait()
+	if len(run.Stderr) > 0 {
+		fmt.Fprintf(b.stdout, "\n%s\n", run.Stderr)
+	}
 
 	return result, nil
 }
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeBusted creates Busted running a shell script instead of the real one.
+func newFakeBusted(t *testing.T, script string) (*Busted, *bytes.Buffer) {
+	path := filepath.Join(t.TempDir(), "busted")
+	assert.Nil(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o700))
+
+	b, err := NewBusted()
+	assert.Nil(t, err)
+
+	var stdout bytes.Buffer
+	b.Cmd = path
+	b.stdout = &stdout
+
+	return b, &stdout
+}
+
+func TestBusted_Test(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		stdout   string
+		exitCode int
+		failed   bool
+	}{
+		{
+			"passed",
+			"echo '++'",
+			"++\n",
+			0,
+			false,
+		},
+		{
+			"passed with stderr",
+			"echo '++'; echo 'deprecated' >&2",
+			"++\n\ndeprecated\n",
+			0,
+			false,
+		},
+		{
+			"failed tests",
+			"echo '+-'; exit 1",
+			"+-\n",
+			1,
+			false,
+		},
+		{
+			"failed tests with stderr",
+			"echo '+'; echo 'crashed' >&2; exit 1",
+			"+\n\ncrashed\n",
+			1,
+			false,
+		},
+		{
+			"failure",
+			"echo 'crashed' >&2; exit 1",
+			"",
+			1,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, stdout := newFakeBusted(t, tt.script)
+
+			result, err := b.Test()
+			assert.Equal(t, tt.stdout, stdout.String())
+			assert.Equal(t, tt.exitCode, result.ExitCode)
+
+			if !tt.failed {
+				assert.Nil(t, err)
+				return
+			}
+
+			var toolErr *ToolError
+			assert.True(t, errors.As(err, &toolErr))
+			assert.Equal(t, "crashed", toolErr.Stderr)
+		})
+	}
+}
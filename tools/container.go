@@ -0,0 +1,230 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContainerMode represents how long a Container is kept running.
+type ContainerMode int
+
+const (
+	// ContainerModeNone represents a mode where each tool invocation starts a
+	// new container using "docker run".
+	ContainerModeNone ContainerMode = iota
+
+	// ContainerModeSession represents a mode where a single container is
+	// started on the first use and removed by Close.
+	ContainerModeSession
+
+	// ContainerModeProject represents a mode where a single container is shared
+	// by all sessions in the same project and is only removed after being idle
+	// for IdleTimeout.
+	ContainerModeProject
+)
+
+// ContainerModes holds all supported container mode names.
+var ContainerModes = []string{"none", "session", "project"}
+
+// DefaultIdleTimeout holds the default time a container is kept running after
+// its last use.
+const DefaultIdleTimeout = 10 * time.Minute
+
+// containerHeartbeat holds a path to the file inside a container which is
+// touched to postpone the idle timeout. Each command run in the container also
+// holds a "<heartbeat>.<pid>" lock file while running, so the heartbeat keeps
+// being touched until all of them finish.
+const containerHeartbeat = "/tmp/.mod-cli"
+
+// containerInterval holds the number of seconds between idle checks.
+const containerInterval = 5
+
+// idleScript returns a script which keeps running until the heartbeat file
+// hasn't been touched for idle seconds. Lock files of live processes touch it
+// on each check, so running commands are never interrupted.
+func idleScript(heartbeat string, idle, interval int) string {
+	return fmt.Sprintf(
+		`touch %[1]s
+while :; do
+  for f in %[1]s.*; do
+    [ -e "$f" ] && [ -d "/proc/${f##*.}" ] && touch %[1]s
+  done
+  [ $(( $(date +%%s) - $(date -r %[1]s +%%s) )) -lt %[2]d ] || break
+  sleep %[3]d
+done`,
+		heartbeat,
+		idle,
+		interval,
+	)
+}
+
+// execScript returns a script which runs the command from its arguments while
+// holding a lock file. It exits with the same code as the command.
+func execScript(heartbeat string) string {
+	return fmt.Sprintf(
+		`touch %[1]s %[1]s.$$; "$@"; code=$?; rm -f %[1]s.$$; exit $code`,
+		heartbeat,
+	)
+}
+
+// ParseContainerMode parses a container mode name like "session".
+func ParseContainerMode(name string) (ContainerMode, error) {
+	for i, n := range ContainerModes {
+		if n == name {
+			return ContainerMode(i), nil
+		}
+	}
+	return ContainerModeNone, fmt.Errorf("unknown container mode: %s", name)
+}
+
+// String returns a container mode name like "session".
+func (m ContainerMode) String() string {
+	if int(m) < len(ContainerModes) {
+		return ContainerModes[m]
+	}
+	return ""
+}
+
+// Container represents a long-lived container shared by tools with the same
+// Dockerized settings. Tools are run inside it using "docker exec".
+type Container struct {
+	// IdleTimeout holds the time after which an unused container stops itself.
+	IdleTimeout time.Duration
+
+	// Mode holds a container mode.
+	Mode ContainerMode
+
+	// Name holds a container name.
+	Name string
+
+	dockerized Dockerized
+	err        error
+	mu         sync.Mutex
+	started    bool
+}
+
+// NewContainer creates a new Container instance for the provided Dockerized
+// settings. The name is derived from the settings, so the same project gets
+// the same container in ContainerModeProject.
+func NewContainer(mode ContainerMode, dockerized *Dockerized) *Container {
+	hash := sha256.Sum256([]byte(strings.Join(dockerized.runArgs(), " ")))
+	name := "mod-cli-" + hex.EncodeToString(hash[:])[:12]
+	if mode == ContainerModeSession {
+		name = fmt.Sprintf("%s-%d", name, os.Getpid())
+	}
+
+	return &Container{
+		IdleTimeout: DefaultIdleTimeout,
+		Mode:        mode,
+		Name:        name,
+		dockerized:  *dockerized,
+	}
+}
+
+func (c *Container) docker(arg ...string) *exec.Cmd {
+	return c.dockerized.command(arg...)
+}
+
+func (c *Container) isRunning() (exists bool, running bool) {
+	out, err := c.docker("inspect", "-f", "{{.State.Running}}", c.Name).Output()
+	if err != nil {
+		return false, false
+	}
+	return true, strings.TrimSpace(string(out)) == "true"
+}
+
+func (c *Container) touch() error {
+	return c.docker(append(c.ExecArgs(), "true")...).Run()
+}
+
+func (c *Container) run() error {
+	idle := int(c.IdleTimeout.Seconds())
+	if idle <= 0 {
+		idle = int(DefaultIdleTimeout.Seconds())
+	}
+
+	a := []string{"run", "-d", "--rm", "--name", c.Name}
+	a = append(a, c.dockerized.flags()...)
+	a = append(a, c.dockerized.Image, "sh", "-c", idleScript(containerHeartbeat, idle, containerInterval))
+
+	out, err := c.docker(a...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to start container %s: %s", c.Name, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// Start starts the container unless it's already running. It's safe to call
+// from multiple goroutines and only the first call does the work.
+func (c *Container) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.started {
+		return c.err
+	}
+	c.started = true
+
+	exists, running := c.isRunning()
+	if running {
+		c.err = c.touch()
+		return c.err
+	}
+
+	if exists {
+		_ = c.docker("rm", "-f", c.Name).Run()
+	}
+
+	if err := c.run(); err != nil {
+		// another session may have started the same project container
+		if _, running := c.isRunning(); !running {
+			c.err = err
+			return c.err
+		}
+	}
+
+	return nil
+}
+
+// ExecArgs returns "docker exec" arguments to run a command in the container.
+// The command is wrapped to postpone the idle timeout while it's running.
+func (c *Container) ExecArgs() []string {
+	a := []string{"exec", "-i"}
+	if !c.dockerized.Rootless && len(c.dockerized.User) > 0 {
+		a = append(a, "-u", c.dockerized.User)
+	}
+	if wd := c.dockerized.workDir(); len(wd) > 0 {
+		a = append(a, "-w", wd)
+	}
+	return append(a, c.Name, "sh", "-c", execScript(containerHeartbeat), "sh")
+}
+
+// Close removes the container in ContainerModeSession. In ContainerModeProject,
+// it only postpones the idle timeout, so the container can be reused later.
+func (c *Container) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.started || c.err != nil {
+		return nil
+	}
+	c.started = false
+
+	if c.Mode == ContainerModeProject {
+		return c.touch()
+	}
+
+	if err := c.docker("rm", "-f", c.Name).Run(); err != nil {
+		return errors.New("failed to remove container " + c.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startIdleScript starts the idle script with a 2-second idle timeout and
+// returns a channel closed when it exits.
+func startIdleScript(t *testing.T, heartbeat string) <-chan struct{} {
+	cmd := exec.Command("sh", "-c", idleScript(heartbeat, 2, 1))
+	assert.Nil(t, cmd.Start())
+
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+	})
+
+	return done
+}
+
+func isDone(done <-chan struct{}, timeout time.Duration) bool {
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func TestIdleScript(t *testing.T) {
+	heartbeat := filepath.Join(t.TempDir(), ".mod-cli")
+
+	done := startIdleScript(t, heartbeat)
+	assert.True(t, isDone(done, 6*time.Second), "should stop when idle")
+}
+
+func TestIdleScript_RunningCommand(t *testing.T) {
+	heartbeat := filepath.Join(t.TempDir(), ".mod-cli")
+
+	// a long-running command holding a lock file
+	cmd := exec.Command("sh", "-c", execScript(heartbeat), "sh", "sleep", "30")
+	assert.Nil(t, cmd.Start())
+	defer func() {
+		_ = cmd.Process.Kill()
+	}()
+
+	lock := fmt.Sprintf("%s.%d", heartbeat, cmd.Process.Pid)
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(lock)
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	done := startIdleScript(t, heartbeat)
+	assert.False(t, isDone(done, 5*time.Second), "should keep running while a command is running")
+
+	// a stale lock file of a killed command doesn't keep it running
+	assert.Nil(t, cmd.Process.Kill())
+	_ = cmd.Wait()
+	assert.FileExists(t, lock)
+	assert.True(t, isDone(done, 6*time.Second), "should stop when the command is finished")
+}
+
+func TestExecScript(t *testing.T) {
+	heartbeat := filepath.Join(t.TempDir(), ".mod-cli")
+
+	cmd := exec.Command("sh", "-c", execScript(heartbeat), "sh", "sh", "-c", "echo \"$1\"; exit 3", "sh", "a b")
+	out, err := cmd.Output()
+
+	var exitErr *exec.ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 3, exitErr.ExitCode())
+	assert.Equal(t, "a b\n", string(out))
+	assert.FileExists(t, heartbeat)
+
+	locks, _ := filepath.Glob(heartbeat + ".*")
+	assert.Empty(t, locks)
+}
+
+func TestParseContainerMode(t *testing.T) {
+	for i, name := range []string{"none", "session", "project"} {
+		m, err := ParseContainerMode(name)
+		assert.Nil(t, err)
+		assert.Equal(t, ContainerMode(i), m)
+		assert.Equal(t, name, m.String())
+	}
+
+	_, err := ParseContainerMode("forever")
+	assert.EqualError(t, err, "unknown container mode: forever")
+}
+
+func newTestDockerized(runtime Runtime, rootless bool) *Dockerized {
+	d := NewDockerized()
+	d.Image = "image"
+	d.Volume = "/src/mod"
+	d.Runtime = runtime
+	d.Rootless = rootless
+	return d
+}
+
+func TestNewContainer(t *testing.T) {
+	project := NewContainer(ContainerModeProject, newTestDockerized(RuntimeDocker, false))
+	assert.Regexp(t, `^mod-cli-[0-9a-f]{12}$`, project.Name)
+	assert.Equal(t, DefaultIdleTimeout, project.IdleTimeout)
+
+	same := NewContainer(ContainerModeProject, newTestDockerized(RuntimeDocker, false))
+	assert.Equal(t, project.Name, same.Name, "same settings should share a container")
+
+	other := NewContainer(ContainerModeProject, newTestDockerized(RuntimePodman, true))
+	assert.NotEqual(t, project.Name, other.Name)
+
+	session := NewContainer(ContainerModeSession, newTestDockerized(RuntimeDocker, false))
+	assert.Equal(t, fmt.Sprintf("%s-%d", project.Name, os.Getpid()), session.Name)
+}
+
+func TestContainer_ExecArgs(t *testing.T) {
+	script := execScript(containerHeartbeat)
+
+	tests := []struct {
+		name     string
+		runtime  Runtime
+		rootless bool
+		user     string
+		expected []string
+	}{
+		{"docker", RuntimeDocker, false, "dst-mod", []string{"exec", "-i", "-u", "dst-mod", "-w", "/opt/mod"}},
+		{"docker without user", RuntimeDocker, false, "", []string{"exec", "-i", "-w", "/opt/mod"}},
+		{"docker rootless", RuntimeDocker, true, "dst-mod", []string{"exec", "-i", "-w", "/opt/mod"}},
+		{"podman", RuntimePodman, false, "1000", []string{"exec", "-i", "-u", "1000", "-w", "/opt/mod"}},
+		{"podman rootless", RuntimePodman, true, "dst-mod", []string{"exec", "-i", "-w", "/opt/mod"}},
+		{"nerdctl", RuntimeNerdctl, false, "dst-mod", []string{"exec", "-i", "-u", "dst-mod", "-w", "/opt/mod"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDockerized(tt.runtime, tt.rootless)
+			d.User = tt.user
+
+			c := NewContainer(ContainerModeSession, d)
+			expected := append(tt.expected, c.Name, "sh", "-c", script, "sh")
+			assert.Equal(t, expected, c.ExecArgs())
+		})
+	}
+}
+
+func TestContainer_Start(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+
+	// a runtime without any containers which logs the arguments of each call
+	script := fmt.Sprintf("#!/bin/sh\nprintf '%%s\\n' \"$@\" '---' >> %s\n[ \"$1\" = inspect ] && exit 1\nexit 0\n", log)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "podman"), []byte(script), 0o755))
+	t.Setenv("PATH", dir)
+
+	c := NewContainer(ContainerModeProject, newTestDockerized(RuntimePodman, true))
+	c.IdleTimeout = time.Minute
+
+	assert.Nil(t, c.Start())
+	assert.Nil(t, c.Start(), "only the first call should start the container")
+
+	out, err := os.ReadFile(log)
+	assert.Nil(t, err)
+
+	calls := strings.Split(strings.TrimSuffix(string(out), "---\n"), "---\n")
+	if assert.Len(t, calls, 2) {
+		assert.Equal(t, fmt.Sprintf("inspect\n-f\n{{.State.Running}}\n%s\n", c.Name), calls[0])
+		assert.Equal(t, strings.Join([]string{
+			"run", "-d", "--rm", "--name", c.Name,
+			"--userns=keep-id",
+			"-v", "/src/mod:/opt/mod:z",
+			"-w", "/opt/mod",
+			"image", "sh", "-c", idleScript(containerHeartbeat, 60, containerInterval),
+		}, "\n")+"\n", calls[1])
+	}
+}
@@ -0,0 +1,256 @@
+package tools
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Custom represents a user-defined tool. It runs an arbitrary command and
+// parses its output using a regular expression.
+type Custom struct {
+	Tool
+
+	// Args holds arguments passed before the files when linting or checking.
+	Args []string
+
+	// FixArgs holds arguments passed before the files when fixing. Fixing is
+	// not supported when empty.
+	FixArgs []string
+
+	// Ext holds extensions of files to pass when none are provided. All files
+	// are passed when empty.
+	Ext []string
+
+	// Output holds a regular expression to parse each output line. The named
+	// groups "file", "line", "column" and "message" are recognized.
+	Output *regexp.Regexp
+
+	// VersionArgs holds arguments to print a version.
+	//
+	// Default: --version
+	VersionArgs []string
+}
+
+// NewCustom creates a new Custom instance.
+func NewCustom(name, cmd string) (*Custom, error) {
+	tool, err := NewTool(name, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return &Custom{
+		Tool:        *tool,
+		VersionArgs: []string{"--version"},
+	}, nil
+}
+
+func (c *Custom) parseVersion(str string) (string, error) {
+	str = cleanString(str)
+	if len(str) == 0 {
+		return "", errors.New("no output")
+	}
+
+	if ver := versionRegex.FindString(str); len(ver) > 0 {
+		return ver, nil
+	}
+
+	return strings.TrimSpace(strings.Split(str, "\n")[0]), nil
+}
+
+func (c *Custom) prepareArg(args []string, files ...string) []string {
+	a := append([]string{}, args...)
+	return append(a, files...)
+}
+
+func (c *Custom) submatch(matches []string, name string) string {
+	i := c.Output.SubexpIndex(name)
+	if i < 0 || i >= len(matches) {
+		return ""
+	}
+	return cleanString(matches[i])
+}
+
+func (c *Custom) scan(args []string, files []string, fn func(string)) (RunResult, error) {
+	return runLines(c.ExecCommand(c.prepareArg(args, files...)...), fn)
+}
+
+// LoadVersion loads a version.
+func (c *Custom) LoadVersion() (string, error) {
+	cmd := c.ExecCommand(c.VersionArgs...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", err
+	}
+
+	ver, err := c.parseVersion(string(out))
+	if err != nil {
+		return ver, err
+	}
+	c.version = ver
+
+	return ver, nil
+}
+
+func (c *Custom) lint(files []string) (result Lint, err error) {
+	var stdoutLines []string
+	index := map[string]int{}
+
+	run, err := c.scan(c.Args, files, func(line string) {
+		stdoutLines = append(stdoutLines, line)
+
+		matches := c.Output.FindStringSubmatch(line)
+		if matches == nil {
+			return
+		}
+
+		name := c.submatch(matches, "file")
+		startLine, _ := strconv.Atoi(c.submatch(matches, "line"))
+		endLine, _ := strconv.Atoi(c.submatch(matches, "column"))
+
+		description := c.submatch(matches, "message")
+		if len(description) == 0 {
+			description = cleanString(line)
+		}
+
+		i, ok := index[name]
+		if !ok {
+			i = len(result.Files)
+			index[name] = i
+			result.Files = append(result.Files, LintFile{
+				Path:   name,
+				Issues: []LintFileIssue{},
+			})
+		}
+
+		result.Files[i].Issues = append(result.Files[i].Issues, LintFileIssue{
+			Name:        name,
+			StartLine:   startLine,
+			EndLine:     endLine,
+			Description: description,
+		})
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.RunResult = run
+	result.Stdout = strings.TrimSpace(strings.Join(stdoutLines, "\n"))
+
+	return result, run.check(c.Name(), result.HasIssues())
+}
+
+func (c *Custom) check(files []string) (result Format, err error) {
+	run, err := c.scan(c.Args, files, func(line string) {
+		path := strings.TrimSpace(line)
+
+		if c.Output != nil {
+			matches := c.Output.FindStringSubmatch(line)
+			if matches == nil {
+				return
+			}
+
+			if file := c.submatch(matches, "file"); len(file) > 0 {
+				path = file
+			}
+		}
+
+		if len(path) == 0 {
+			return
+		}
+
+		result.Files = append(result.Files, FormatFile{
+			Path:  path,
+			State: FileStateWarning,
+		})
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.RunResult = run
+	return result, run.check(c.Name(), result.HasIssues())
+}
+
+func (c *Custom) fix(files []string) (result Format, err error) {
+	result, err = c.check(files)
+	if err != nil {
+		return result, err
+	}
+
+	run, err := c.scan(c.FixArgs, files, func(string) {})
+	if err != nil {
+		return result, err
+	}
+
+	result.RunResult = run
+	if err := run.check(c.Name(), false); err != nil {
+		return result, err
+	}
+
+	for i := 0; i < len(result.Files); i++ {
+		result.Files[i].State = FileStateSuccess
+	}
+
+	return result, nil
+}
+
+// Lint lints provided files. Each output line matching Output becomes an
+// issue in the file from the "file" group. A non-zero exit code is treated as
+// a failure unless at least one issue has been found.
+func (c *Custom) Lint(arg ...string) (result Lint, err error) {
+	if c.Output == nil {
+		return result, errors.New("output regex is required to lint")
+	}
+
+	files, err := c.files(arg, c.Ext...)
+	if err != nil {
+		return result, err
+	}
+
+	err = eachBatch(files, func(batch []string) error {
+		lint, err := c.lint(batch)
+		result.merge(lint)
+		return err
+	})
+
+	return result, err
+}
+
+// Check checks formatting in the provided files. Each non-empty output line is
+// treated as a path of a file with issues unless Output is set, in which case
+// only matching lines are used and the "file" group holds the path.
+func (c *Custom) Check(arg ...string) (result Format, err error) {
+	files, err := c.files(arg, c.Ext...)
+	if err != nil {
+		return result, err
+	}
+
+	err = eachBatch(files, func(batch []string) error {
+		format, err := c.check(batch)
+		result.merge(format)
+		return err
+	})
+
+	return result, err
+}
+
+// Fix fixes formatting in the provided files.
+func (c *Custom) Fix(arg ...string) (result Format, err error) {
+	if len(c.FixArgs) == 0 {
+		return result, errors.New("fixing is not supported")
+	}
+
+	files, err := c.files(arg, c.Ext...)
+	if err != nil {
+		return result, err
+	}
+
+	err = eachBatch(files, func(batch []string) error {
+		format, err := c.fix(batch)
+		result.merge(format)
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,181 @@
+package tools
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newShCustom creates a Custom tool running the provided shell script with the
+// files as its arguments.
+func newShCustom(t *testing.T, script string) *Custom {
+	c, err := NewCustom("Test", "sh")
+	assert.Nil(t, err)
+	c.Args = []string{"-c", script, "sh"}
+	return c
+}
+
+// chdirFiles changes the working directory for the rest of the test to a new
+// one with the provided Lua files.
+func chdirFiles(t *testing.T, names ...string) {
+	dir := t.TempDir()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.Nil(t, os.WriteFile(path, []byte("return"), 0o600))
+	}
+
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+}
+
+func TestNewCustom(t *testing.T) {
+	c, err := NewCustom("Selene", "selene")
+	assert.Nil(t, err)
+	assert.Equal(t, "Selene", c.Name())
+	assert.Equal(t, "selene", c.Cmd)
+	assert.Equal(t, []string{"--version"}, c.VersionArgs)
+	assert.Empty(t, c.Args)
+	assert.Nil(t, c.Output)
+}
+
+func TestCustom_parseVersion(t *testing.T) {
+	tests := []struct {
+		str      string
+		expected string
+	}{
+		{"selene 0.20.0", "0.20.0"},
+		{"Selene v0.20.0\nMore info", "v0.20.0"},
+		{"\x1b[1mtool 1.2.3-beta\x1b[0m", "1.2.3-beta"},
+		{"  nightly build\nsecond line", "nightly build"},
+	}
+
+	c, err := NewCustom("Test", "sh")
+	assert.Nil(t, err)
+
+	for _, tt := range tests {
+		ver, err := c.parseVersion(tt.str)
+		assert.Nil(t, err)
+		assert.Equalf(t, tt.expected, ver, "Version %q", tt.str)
+	}
+
+	_, err = c.parseVersion(" \n")
+	assert.EqualError(t, err, "no output")
+}
+
+func TestCustom_LoadVersion(t *testing.T) {
+	c, err := NewCustom("Test", "sh")
+	assert.Nil(t, err)
+	c.VersionArgs = []string{"-c", "echo Test 2.5.1"}
+
+	ver, err := c.LoadVersion()
+	assert.Nil(t, err)
+	assert.Equal(t, "2.5.1", ver)
+	assert.Equal(t, "2.5.1", c.Version())
+
+	c.VersionArgs = []string{"-c", "exit 1"}
+	_, err = c.LoadVersion()
+	assert.NotNil(t, err)
+}
+
+func TestCustom_prepareArg(t *testing.T) {
+	c, err := NewCustom("Test", "sh")
+	assert.Nil(t, err)
+
+	args := []string{"--quiet"}
+	assert.Equal(t, []string{"--quiet", "a.lua", "b.lua"}, c.prepareArg(args, "a.lua", "b.lua"))
+	assert.Equal(t, []string{"--quiet"}, args, "arguments shouldn't be modified")
+}
+
+func TestCustom_Lint(t *testing.T) {
+	chdirFiles(t, "a.lua", "b.lua", "readme.txt")
+
+	c := newShCustom(t, `for f; do echo "$f:1:2: first"; echo "$f:3:4:"; done; echo "summary"`)
+	c.Ext = []string{".lua"}
+	c.Output = regexp.MustCompile(`^(?P<file>[^:]+):(?P<line>\d+):(?P<column>\d+):\s*(?P<message>.*)$`)
+
+	result, err := c.Lint()
+	assert.Nil(t, err)
+	assert.Equal(t, []LintFile{
+		{Path: "a.lua", Issues: []LintFileIssue{
+			{Name: "a.lua", StartLine: 1, EndLine: 2, Description: "first"},
+			{Name: "a.lua", StartLine: 3, EndLine: 4, Description: "a.lua:3:4:"},
+		}},
+		{Path: "b.lua", Issues: []LintFileIssue{
+			{Name: "b.lua", StartLine: 1, EndLine: 2, Description: "first"},
+			{Name: "b.lua", StartLine: 3, EndLine: 4, Description: "b.lua:3:4:"},
+		}},
+	}, result.Files)
+	assert.Contains(t, result.Stdout, "summary")
+}
+
+func TestCustom_Lint_NoOutput(t *testing.T) {
+	c := newShCustom(t, "true")
+
+	_, err := c.Lint()
+	assert.EqualError(t, err, "output regex is required to lint")
+}
+
+func TestCustom_Lint_Failure(t *testing.T) {
+	chdirFiles(t, "a.lua")
+
+	c := newShCustom(t, `echo "crashed" >&2; exit 3`)
+	c.Output = regexp.MustCompile(`^(?P<file>[^:]+):(?P<line>\d+): (?P<message>.*)$`)
+
+	_, err := c.Lint("a.lua")
+
+	var toolErr *ToolError
+	if assert.True(t, errors.As(err, &toolErr)) {
+		assert.Equal(t, 3, toolErr.ExitCode)
+		assert.Equal(t, "crashed", toolErr.Stderr)
+	}
+}
+
+func TestCustom_Check(t *testing.T) {
+	chdirFiles(t, "a.lua", "b.lua")
+
+	c := newShCustom(t, `for f; do echo "$f"; done; echo`)
+
+	result, err := c.Check("a.lua", "b.lua")
+	assert.Nil(t, err)
+	assert.Equal(t, []FormatFile{
+		{Path: "a.lua", State: FileStateWarning},
+		{Path: "b.lua", State: FileStateWarning},
+	}, result.Files)
+
+	c = newShCustom(t, `for f; do echo "Would reformat: $f"; done; echo "Done"`)
+	c.Output = regexp.MustCompile(`^Would reformat: (?P<file>.+)$`)
+
+	result, err = c.Check("a.lua")
+	assert.Nil(t, err)
+	assert.Equal(t, []FormatFile{{Path: "a.lua", State: FileStateWarning}}, result.Files)
+}
+
+func TestCustom_Fix(t *testing.T) {
+	chdirFiles(t, "a.lua", "b.lua")
+
+	c := newShCustom(t, `echo "$1"`)
+
+	_, err := c.Fix("a.lua")
+	assert.EqualError(t, err, "fixing is not supported")
+
+	c.FixArgs = []string{"-c", `for f; do echo fixed > "$f"; done`, "sh"}
+
+	result, err := c.Fix("a.lua", "b.lua")
+	assert.Nil(t, err)
+	assert.Equal(t, []FormatFile{{Path: "a.lua", State: FileStateSuccess}}, result.Files)
+
+	for _, name := range []string{"a.lua", "b.lua"} {
+		content, err := os.ReadFile(name)
+		assert.Nil(t, err)
+		assert.Equal(t, "fixed\n", string(content))
+	}
+}
@@ -3,12 +3,26 @@ package tools
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 )
 
 // Docker represents a Docker tool.
 type Docker struct {
 	Tool
+
+	// Bundle holds a path to a tarball created using SaveImages to load missing
+	// images from instead of pulling them.
+	Bundle string
+}
+
+func init() {
+	Register(Registration{
+		ID: "docker",
+		New: func() (Tooler, error) {
+			return NewDocker()
+		},
+	})
 }
 
 // NewDocker creates a new Docker instance.
@@ -22,9 +36,19 @@ func NewDocker() (*Docker, error) {
 	}, nil
 }
 
+// SetRuntime sets a container runtime to use instead of Docker.
+func (d *Docker) SetRuntime(runtime Runtime) {
+	d.Cmd = runtime.String()
+	d.name = runtime.Name()
+}
+
 func (d *Docker) parseVersion(str string) (string, error) {
 	match := dockerVersionRegex.FindStringSubmatch(str)
 	if len(match) != 3 {
+		// Podman and nerdctl only print "<name> version <version>"
+		if ver := versionRegex.FindString(str); len(ver) > 0 {
+			return ver, nil
+		}
 		return "", errors.New("not found")
 	}
 	version := strings.TrimSpace(match[1])
@@ -48,3 +72,26 @@ func (d *Docker) LoadVersion() (string, error) {
 
 	return ver, nil
 }
+
+// SaveImages saves images into a tarball which can be loaded later on another
+// machine using LoadImages.
+func (d *Docker) SaveImages(file string, images ...string) error {
+	a := append([]string{"save", "-o", file}, images...)
+	if out, err := d.ExecCommand(a...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to save images: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// LoadImages loads images from a tarball created using SaveImages.
+func (d *Docker) LoadImages(file string) error {
+	if _, err := os.Stat(file); err != nil {
+		return err
+	}
+
+	if out, err := d.ExecCommand("load", "-i", file).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to load images: %s", strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
@@ -1,26 +1,47 @@
 package tools
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
+	"sort"
 )
 
 // Dockerized represents a Docker arguments to run a tool.
 type Dockerized struct {
+	// Env holds environment variables to set in a container.
+	Env map[string]string
+
+	// ExtraArgs holds extra arguments passed before the image.
+	ExtraArgs []string
+
 	// Image hold the image name and tag.
 	//
 	// Default: dstmodders/dst-mod:latest
 	Image string
 
+	// Mounts holds extra volumes to mount in the "src:dest[:options]" format.
+	Mounts []string
+
+	// Network holds a network mode like "none" or "host".
+	Network string
+
 	// Remove sets whether a container should be removed right after running.
 	//
 	// Default: true
 	Remove bool
 
+	// Rootless sets whether the runtime runs in rootless mode. In this mode, User
+	// is ignored and the container runs as the current user, so the files
+	// written to Volume keep the right owner.
+	Rootless bool
+
+	// Runtime holds a container runtime.
+	//
+	// Default: RuntimeDocker
+	Runtime Runtime
+
 	// User holds a username or UID to run a container as.
 	//
 	// Default: dst-mod
@@ -48,70 +69,96 @@ func (d *Dockerized) Args() []string {
 	return d.args
 }
 
+func (d *Dockerized) command(arg ...string) *exec.Cmd {
+	return exec.Command(d.Runtime.String(), arg...)
+}
+
 // IsImageAvailable checks whether an image is available locally.
 func (d *Dockerized) IsImageAvailable() bool {
-	cmd := exec.Command("docker", "image", "ls")
-
-	stdout, _ := cmd.StdoutPipe()
+	return d.command("image", "inspect", d.Image).Run() == nil
+}
 
-	if err := cmd.Start(); err != nil {
-		return false
+func (d *Dockerized) volume() string {
+	if len(d.Volume) > 0 {
+		return d.Volume
 	}
+	wd, _ := os.Getwd()
+	return wd
+}
 
-	image := d.Image
-	tag := "latest"
+func (d *Dockerized) workDir() string {
+	volume := d.volume()
+	if len(volume) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("/opt/%s", filepath.Base(volume))
+}
 
-	split := strings.Split(d.Image, ":")
-	if len(split) == 2 {
-		image = split[0]
-		tag = split[1]
+// flags returns the user, volume and other flags shared by "docker run" and the
+// container started for ContainerModeSession or ContainerModeProject.
+func (d *Dockerized) flags() (result []string) {
+	if d.Rootless {
+		result = append(result, d.Runtime.rootlessFlags()...)
+	} else if len(d.User) > 0 {
+		result = append(result, "-u")
+		result = append(result, d.User)
 	}
 
-	scanner := bufio.NewScanner(stdout)
-	scanner.Split(bufio.ScanLines)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, image) && strings.Contains(line, tag) {
-			return true
+	if volume := d.volume(); len(volume) > 0 {
+		v := fmt.Sprintf("%s:%s", volume, d.workDir())
+		if opts := d.Runtime.volumeOptions(); len(opts) > 0 {
+			v += ":" + opts
 		}
+		result = append(result, "-v")
+		result = append(result, v)
+		result = append(result, "-w")
+		result = append(result, d.workDir())
 	}
 
-	_ = cmd.Wait()
+	for _, mount := range d.Mounts {
+		result = append(result, "-v")
+		result = append(result, mount)
+	}
 
-	return false
-}
+	keys := make([]string, 0, len(d.Env))
+	for key := range d.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-// PrepareArgs prepare arguments to return later using Args.
-func (d *Dockerized) PrepareArgs() (result []string, err error) {
-	volume := d.Volume
+	for _, key := range keys {
+		result = append(result, "-e")
+		result = append(result, fmt.Sprintf("%s=%s", key, d.Env[key]))
+	}
 
-	if len(volume) == 0 {
-		volume, err = os.Getwd()
-		if err != nil {
-			return result, err
-		}
+	if len(d.Network) > 0 {
+		result = append(result, "--network")
+		result = append(result, d.Network)
 	}
 
-	result = []string{"run"}
+	return append(result, d.ExtraArgs...)
+}
+
+func (d *Dockerized) runArgs() []string {
+	result := []string{"run"}
 
 	if d.Remove {
 		result = append(result, "--rm")
 	}
 
-	if len(d.User) > 0 {
-		result = append(result, "-u")
-		result = append(result, d.User)
-	}
+	result = append(result, d.flags()...)
+	return append(result, d.Image)
+}
 
-	if len(volume) > 0 {
-		base := filepath.Base(volume)
-		result = append(result, "-v")
-		result = append(result, fmt.Sprintf("%s:/opt/%s:z", volume, base))
-		result = append(result, "-w")
-		result = append(result, fmt.Sprintf("/opt/%s", base))
+// PrepareArgs prepare arguments to return later using Args.
+func (d *Dockerized) PrepareArgs() (result []string, err error) {
+	if len(d.Volume) == 0 {
+		if _, err = os.Getwd(); err != nil {
+			return result, err
+		}
 	}
 
-	result = append(result, d.Image)
+	result = d.runArgs()
 	d.args = result
 
 	return result, err
@@ -119,8 +166,7 @@ func (d *Dockerized) PrepareArgs() (result []string, err error) {
 
 // PullImage pulls an image.
 func (d *Dockerized) PullImage() bool {
-	//nolint:gosec
-	cmd := exec.Command("docker", "pull", d.Image)
+	cmd := d.command("pull", d.Image)
 
 	if err := cmd.Start(); err != nil {
 		return false
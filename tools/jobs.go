@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"runtime"
+	"sync"
+)
+
+// SetJobs sets the number of tools run in parallel. Zero or a negative value
+// uses the number of CPUs.
+func (t *Tools) SetJobs(jobs int) {
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	t.jobs = jobs
+}
+
+// Jobs gets the number of tools run in parallel.
+func (t *Tools) Jobs() int {
+	if t.jobs <= 0 {
+		return 1
+	}
+	return t.jobs
+}
+
+// Each calls fn for each of the provided tool IDs using a pool of workers
+// limited by Jobs and waits for all calls to return. The index of the ID is
+// passed along, so the results can be collected in a stable order.
+func (t *Tools) Each(ids []string, fn func(i int, id string, tool Tooler)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, t.Jobs())
+
+	for i, id := range ids {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, id string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i, id, t.tools[id])
+		}(i, id)
+	}
+
+	wg.Wait()
+}
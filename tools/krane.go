@@ -5,6 +5,16 @@ type Krane struct {
 	Ktools
 }
 
+func init() {
+	Register(Registration{
+		ID:         "krane",
+		Dockerized: true,
+		New: func() (Tooler, error) {
+			return NewKrane()
+		},
+	})
+}
+
 // NewKrane creates a new Krane instance.
 func NewKrane() (*Krane, error) {
 	ktools, err := NewKtools("krane", "krane")
@@ -1,10 +1,25 @@
 package tools
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Ktech represents a ktools/ktech tool.
 type Ktech struct {
 	Ktools
 }
 
+func init() {
+	Register(Registration{
+		ID:         "ktech",
+		Dockerized: true,
+		New: func() (Tooler, error) {
+			return NewKtech()
+		},
+	})
+}
+
 // NewKtech creates a new Ktech instance.
 func NewKtech() (*Ktech, error) {
 	ktools, err := NewKtools("ktech", "ktech")
@@ -15,3 +30,19 @@ func NewKtech() (*Ktech, error) {
 		Ktools: *ktools,
 	}, nil
 }
+
+// Convert converts the provided PNG image into TEX along with an XML atlas.
+// Paths should be relative to the working directory, so they can be used
+// through Docker as well.
+func (k *Ktech) Convert(png, tex, atlas string) error {
+	cmd := k.ExecCommand("--atlas", atlas, png, tex)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		str := cleanString(string(out))
+		if len(str) > 0 {
+			return fmt.Errorf("%s: %s", png, strings.ReplaceAll(str, "\n", " "))
+		}
+		return fmt.Errorf("%s: %w", png, err)
+	}
+	return nil
+}
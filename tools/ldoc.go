@@ -10,6 +10,17 @@ type LDoc struct {
 	Tool
 }
 
+func init() {
+	Register(Registration{
+		ID:           "ldoc",
+		Capabilities: CapabilityDoc,
+		Dockerized:   true,
+		New: func() (Tooler, error) {
+			return NewLDoc()
+		},
+	})
+}
+
 // NewLDoc creates a new LDoc instance.
 func NewLDoc() (*LDoc, error) {
 	tool, err := NewTool("LDoc", "ldoc")
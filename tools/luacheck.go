@@ -1,7 +1,6 @@
 package tools
 
 import (
-	"bufio"
 	"bytes"
 	"errors"
 	"io"
@@ -14,6 +13,17 @@ type Luacheck struct {
 	Tool
 }
 
+func init() {
+	Register(Registration{
+		ID:           "luacheck",
+		Capabilities: CapabilityLint,
+		Dockerized:   true,
+		New: func() (Tooler, error) {
+			return NewLuacheck()
+		},
+	})
+}
+
 // NewLuacheck creates a new Luacheck instance.
 func NewLuacheck() (*Luacheck, error) {
 	tool, err := NewTool("Luacheck", "luacheck")
@@ -67,74 +77,86 @@ func (l *Luacheck) LoadVersion() (string, error) {
 }
 
 //nolint:funlen
-// Lint lints provided files.
-func (l *Luacheck) Lint(arg ...string) (result Lint, err error) {
+func (l *Luacheck) lint(files []string) (result Lint, err error) {
 	var stdoutLines []string
 	var file LintFile
+	var parseErr error
 
-	if len(arg) == 0 {
-		files, _, _ := l.workingDir.ListFiles(".lua")
-		arg = append(arg, files...)
-	}
-
-	cmd := l.ExecCommand(arg...)
-	stdout, _ := cmd.StdoutPipe()
-
-	if err := cmd.Start(); err != nil {
-		return result, err
-	}
-
-	scanner := bufio.NewScanner(stdout)
-	scanner.Split(bufio.ScanLines)
-	for scanner.Scan() {
-		line := scanner.Text()
+	run, err := runLines(l.ExecCommand(files...), func(line string) {
 		stdoutLines = append(stdoutLines, line)
-		line = ansiRegex.ReplaceAllString(line, "")
 
-		if luacheckIssueRegex.MatchString(line) {
-			matches := luacheckIssueRegex.FindStringSubmatch(line)
-			if len(matches) != 5 {
-				continue
-			}
+		if parseErr != nil || !luacheckIssueRegex.MatchString(line) {
+			return
+		}
+
+		matches := luacheckIssueRegex.FindStringSubmatch(line)
+		if len(matches) != 5 {
+			return
+		}
 
-			name := cleanString(matches[1])
+		name := cleanString(matches[1])
 
-			if file.Path != name {
+		if file.Path != name {
+			if len(file.Path) > 0 {
 				result.Files = append(result.Files, file)
-				file = LintFile{
-					Path:   name,
-					Issues: []LintFileIssue{},
-				}
 			}
-			file.Path = name
-
-			startLine, err := strconv.Atoi(matches[2])
-			if err != nil {
-				return result, err
+			file = LintFile{
+				Path:   name,
+				Issues: []LintFileIssue{},
 			}
+		}
 
-			endLine, err := strconv.Atoi(matches[3])
-			if err != nil {
-				return result, err
-			}
+		startLine, err := strconv.Atoi(matches[2])
+		if err != nil {
+			parseErr = err
+			return
+		}
 
-			file.Issues = append(file.Issues, LintFileIssue{
-				Name:        name,
-				StartLine:   startLine,
-				EndLine:     endLine,
-				Description: cleanString(matches[4]),
-			})
+		endLine, err := strconv.Atoi(matches[3])
+		if err != nil {
+			parseErr = err
+			return
 		}
+
+		file.Issues = append(file.Issues, LintFileIssue{
+			Name:        name,
+			StartLine:   startLine,
+			EndLine:     endLine,
+			Description: cleanString(matches[4]),
+		})
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if parseErr != nil {
+		return result, parseErr
 	}
 
-	if len(result.Files) > 1 {
-		result.Files = result.Files[1:]
+	if len(file.Path) > 0 {
+		result.Files = append(result.Files, file)
 	}
 
+	result.RunResult = run
 	result.Stdout = strings.Join(stdoutLines, "\n")
 	result.Stdout = strings.TrimSpace(result.Stdout)
 
-	_ = cmd.Wait()
+	return result, run.check(l.Name(), result.HasIssues(), 1, 2)
+}
 
-	return result, nil
+// Lint lints provided files. Exit codes 1 and 2 are used to report warnings
+// and syntax errors, while other codes mean that Luacheck has failed.
+func (l *Luacheck) Lint(arg ...string) (result Lint, err error) {
+	files, err := l.files(arg, ".lua")
+	if err != nil {
+		return result, err
+	}
+
+	err = eachBatch(files, func(batch []string) error {
+		lint, err := l.lint(batch)
+		result.merge(lint)
+		return err
+	})
+
+	return result, err
 }
@@ -1,7 +1,6 @@
 package tools
 
 import (
-	"bufio"
 	"strings"
 )
 
@@ -16,6 +15,17 @@ type Prettier struct {
 	ListDifferent bool
 }
 
+func init() {
+	Register(Registration{
+		ID:           "prettier",
+		Capabilities: CapabilityFormat,
+		Dockerized:   true,
+		New: func() (Tooler, error) {
+			return NewPrettier()
+		},
+	})
+}
+
 // NewPrettier creates a new Prettier instance.
 func NewPrettier() (*Prettier, error) {
 	tool, err := NewTool("Prettier", "prettier")
@@ -34,11 +44,6 @@ func (p *Prettier) parseVersion(str string) (string, error) {
 }
 
 func (p *Prettier) prepareArg(write bool, files ...string) []string {
-	if len(files) == 0 {
-		f, _, _ := p.workingDir.ListFiles(p.DefaultExt...)
-		files = append(files, f...)
-	}
-
 	var a []string
 
 	if p.ListDifferent {
@@ -69,53 +74,72 @@ func (p *Prettier) LoadVersion() (string, error) {
 	return ver, nil
 }
 
-// Check checks formatting in the provided files.
-func (p *Prettier) Check(arg ...string) (result Format, err error) {
-	cmd := p.ExecCommand(p.prepareArg(false, arg...)...)
-	stdout, _ := cmd.StdoutPipe()
-
-	if err := cmd.Start(); err != nil {
-		return result, err
-	}
-
-	scanner := bufio.NewScanner(stdout)
-	scanner.Split(bufio.ScanLines)
-	for scanner.Scan() {
-		line := scanner.Text()
-		line = ansiRegex.ReplaceAllString(line, "")
+func (p *Prettier) check(files []string) (result Format, err error) {
+	run, err := runLines(p.ExecCommand(p.prepareArg(false, files...)...), func(line string) {
+		if len(strings.TrimSpace(line)) == 0 {
+			return
+		}
 		result.Files = append(result.Files, FormatFile{
 			Path:  strings.TrimSpace(line),
 			State: FileStateWarning,
 		})
+	})
+	if err != nil {
+		return result, err
 	}
 
-	_ = cmd.Wait()
-
-	return result, nil
+	result.RunResult = run
+	return result, run.check(p.Name(), result.HasIssues(), 1)
 }
 
-// Fix fixes formatting in the provided files.
-func (p *Prettier) Fix(arg ...string) (result Format, err error) {
-	cmd := p.ExecCommand(p.prepareArg(true, arg...)...)
-	stdout, _ := cmd.StdoutPipe()
-
-	if err := cmd.Start(); err != nil {
-		return result, err
-	}
-
-	scanner := bufio.NewScanner(stdout)
-	scanner.Split(bufio.ScanLines)
-	for scanner.Scan() {
-		line := scanner.Text()
-		line = ansiRegex.ReplaceAllString(line, "")
+func (p *Prettier) fix(files []string) (result Format, err error) {
+	run, err := runLines(p.ExecCommand(p.prepareArg(true, files...)...), func(line string) {
 		splitted := strings.Split(line, " ")
+		if len(strings.TrimSpace(splitted[0])) == 0 {
+			return
+		}
 		result.Files = append(result.Files, FormatFile{
 			Path:  strings.TrimSpace(splitted[0]),
 			State: FileStateSuccess,
 		})
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.RunResult = run
+	return result, run.check(p.Name(), false)
+}
+
+// Check checks formatting in the provided files. Exit code 1 is used to report
+// unformatted files, while other codes mean that Prettier has failed.
+func (p *Prettier) Check(arg ...string) (result Format, err error) {
+	files, err := p.files(arg, p.DefaultExt...)
+	if err != nil {
+		return result, err
+	}
+
+	err = eachBatch(files, func(batch []string) error {
+		format, err := p.check(batch)
+		result.merge(format)
+		return err
+	})
+
+	return result, err
+}
+
+// Fix fixes formatting in the provided files.
+func (p *Prettier) Fix(arg ...string) (result Format, err error) {
+	files, err := p.files(arg, p.DefaultExt...)
+	if err != nil {
+		return result, err
 	}
 
-	_ = cmd.Wait()
+	err = eachBatch(files, func(batch []string) error {
+		format, err := p.fix(batch)
+		result.merge(format)
+		return err
+	})
 
-	return result, nil
+	return result, err
 }
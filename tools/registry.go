@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Capability represents what a tool can be used for. Capabilities can be
+// combined using a bitwise OR.
+type Capability int
+
+const (
+	// CapabilityLint represents a tool which implements Linter.
+	CapabilityLint Capability = 1 << iota
+
+	// CapabilityFormat represents a tool which implements Formatter.
+	CapabilityFormat
+
+	// CapabilityTest represents a tool which implements Tester.
+	CapabilityTest
+
+	// CapabilityDoc represents a documentation generator.
+	CapabilityDoc
+)
+
+var capabilityNames = []struct {
+	capability Capability
+	name       string
+}{
+	{CapabilityLint, "lint"},
+	{CapabilityFormat, "format"},
+	{CapabilityTest, "test"},
+	{CapabilityDoc, "doc"},
+}
+
+// ParseCapability parses a capability name like "lint".
+func ParseCapability(name string) (Capability, error) {
+	for _, c := range capabilityNames {
+		if c.name == name {
+			return c.capability, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown capability: %s", name)
+}
+
+// Has checks if all the provided capabilities are present.
+func (c Capability) Has(other Capability) bool {
+	return c&other == other
+}
+
+// String returns a string representation of a Capability like "lint, format".
+func (c Capability) String() string {
+	var names []string
+	for _, n := range capabilityNames {
+		if c.Has(n.capability) {
+			names = append(names, n.name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "-"
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Linter is the interface that wraps the Lint method.
+type Linter interface {
+	Tooler
+	Lint(...string) (Lint, error)
+}
+
+// Formatter is the interface that wraps the Check and Fix methods.
+type Formatter interface {
+	Tooler
+	Check(...string) (Format, error)
+	Fix(...string) (Format, error)
+}
+
+// Tester is the interface that wraps the Test method.
+type Tester interface {
+	Tooler
+	Test() (Lint, error)
+}
+
+// Registration represents a tool registered using Register.
+type Registration struct {
+	// ID holds a unique identifier used in the configuration like "luacheck".
+	ID string
+
+	// Capabilities holds what the tool can be used for.
+	Capabilities Capability
+
+	// Dockerized sets whether the tool is available in its Docker image.
+	Dockerized bool
+
+	// DockerImage holds a Docker image to use instead of the default one.
+	DockerImage string
+
+	// New creates a new tool instance.
+	New func() (Tooler, error)
+}
+
+var registry = map[string]Registration{}
+
+// Register makes a tool available in all Tools instances created using New. It
+// panics if a tool with the same ID has already been registered.
+func Register(r Registration) {
+	if _, ok := registry[r.ID]; ok {
+		panic("tools: Register called twice for " + r.ID)
+	}
+	registry[r.ID] = r
+}
+
+// Registrations returns all registered tools sorted by ID.
+func Registrations() []Registration {
+	result := make([]Registration, 0, len(registry))
+	for _, r := range registry {
+		result = append(result, r)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testRegistration returns a registration of a custom tool with the provided
+// ID.
+func testRegistration(id string) Registration {
+	return Registration{
+		ID:           id,
+		Capabilities: CapabilityLint,
+		New: func() (Tooler, error) {
+			return NewCustom("Test "+id, "sh")
+		},
+	}
+}
+
+func TestParseCapability(t *testing.T) {
+	for _, name := range []string{"lint", "format", "test", "doc"} {
+		c, err := ParseCapability(name)
+		assert.Nil(t, err)
+		assert.Equal(t, name, c.String())
+	}
+
+	_, err := ParseCapability("build")
+	assert.EqualError(t, err, "unknown capability: build")
+}
+
+func TestCapability(t *testing.T) {
+	c := CapabilityLint | CapabilityFormat
+
+	assert.True(t, c.Has(CapabilityLint))
+	assert.True(t, c.Has(CapabilityLint|CapabilityFormat))
+	assert.False(t, c.Has(CapabilityTest))
+	assert.False(t, c.Has(CapabilityLint|CapabilityTest))
+
+	assert.Equal(t, "lint, format", c.String())
+	assert.Equal(t, "-", Capability(0).String())
+}
+
+func TestRegister(t *testing.T) {
+	r := testRegistration("test-register")
+	Register(r)
+	t.Cleanup(func() {
+		delete(registry, r.ID)
+	})
+
+	assert.PanicsWithValue(t, "tools: Register called twice for test-register", func() {
+		Register(r)
+	})
+
+	ts, err := New()
+	assert.Nil(t, err)
+	assert.NotNil(t, ts.Get(r.ID), "registered tools should be in new instances")
+}
+
+func TestRegistrations(t *testing.T) {
+	registrations := Registrations()
+
+	ids := make([]string, len(registrations))
+	for i, r := range registrations {
+		ids[i] = r.ID
+	}
+
+	assert.True(t, sort.StringsAreSorted(ids))
+	for _, id := range []string{"busted", "docker", "ktech", "luacheck", "prettier", "stylua"} {
+		assert.Contains(t, ids, id)
+	}
+}
+
+func TestTools_Add(t *testing.T) {
+	ts, err := New()
+	assert.Nil(t, err)
+
+	builtin := append([]string{}, ts.IDs()...)
+
+	r := testRegistration("foo")
+	r.DockerImage = "example/foo:1.0"
+	assert.Nil(t, ts.Add(r))
+	assert.Nil(t, ts.Add(testRegistration("bar")))
+
+	assert.Equal(t, append(builtin, "foo", "bar"), ts.IDs(), "IDs should keep the order of adding")
+	assert.Equal(t, "Test foo", ts.Get("foo").Name())
+	assert.Equal(t, "example/foo:1.0", ts.Get("foo").DockerImage())
+
+	reg, ok := ts.Registration("foo")
+	assert.True(t, ok)
+	assert.Equal(t, "foo", reg.ID)
+
+	assert.EqualError(t, ts.Add(testRegistration("foo")), "tool foo already exists")
+	assert.EqualError(t, ts.Add(testRegistration("luacheck")), "tool luacheck already exists")
+	assert.Len(t, ts.IDs(), len(builtin)+2)
+}
+
+func TestTools_Add_Error(t *testing.T) {
+	ts, err := New()
+	assert.Nil(t, err)
+
+	expected := errors.New("failed")
+	err = ts.Add(Registration{
+		ID: "broken",
+		New: func() (Tooler, error) {
+			return nil, expected
+		},
+	})
+
+	assert.Equal(t, expected, err)
+	assert.Nil(t, ts.Get("broken"))
+	assert.NotContains(t, ts.IDs(), "broken")
+}
+
+func TestTools_Unknown(t *testing.T) {
+	ts, err := New()
+	assert.Nil(t, err)
+
+	assert.Nil(t, ts.Get("unknown"))
+
+	_, ok := ts.Registration("unknown")
+	assert.False(t, ok)
+}
+
+func TestTools_Each(t *testing.T) {
+	ts, err := New()
+	assert.Nil(t, err)
+
+	ids := []string{"stylua", "luacheck", "prettier", "busted"}
+
+	for _, jobs := range []int{1, 4} {
+		ts.SetJobs(jobs)
+
+		var mu sync.Mutex
+		var order []string
+		names := make([]string, len(ids))
+
+		ts.Each(ids, func(i int, id string, tool Tooler) {
+			mu.Lock()
+			order = append(order, id)
+			mu.Unlock()
+
+			assert.Equal(t, ids[i], id)
+			names[i] = tool.Name()
+		})
+
+		assert.Equal(t, []string{"StyLua", "Luacheck", "Prettier", "Busted"}, names, "jobs: %d", jobs)
+		if jobs == 1 {
+			assert.Equal(t, ids, order, "a single job should call in order")
+		} else {
+			assert.ElementsMatch(t, ids, order)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// RunResult represents a finished tool run.
+type RunResult struct {
+	// ExitCode holds the exit code.
+	ExitCode int
+
+	// Stderr holds the standard error output with ANSI codes stripped.
+	Stderr string
+}
+
+// ToolError represents a tool failure like a crash, an invalid configuration
+// or a missing file as opposed to the tool finding issues.
+type ToolError struct {
+	// Name holds a tool name.
+	Name string
+
+	// ExitCode holds the exit code.
+	ExitCode int
+
+	// Stderr holds the standard error output.
+	Stderr string
+}
+
+// Error returns the error message including the first non-empty line of the
+// standard error output if there is one.
+func (e *ToolError) Error() string {
+	msg := fmt.Sprintf("%s failed with exit code %d", e.Name, e.ExitCode)
+	if line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(e.Stderr), "\n", 2)[0]); len(line) > 0 {
+		msg = fmt.Sprintf("%s: %s", msg, line)
+	}
+	return msg
+}
+
+// runCommand runs the command passing its standard output to fn while
+// capturing the standard error output. A non-zero exit code is recorded in the
+// result instead of being returned as an error.
+func runCommand(cmd *exec.Cmd, fn func(io.Reader)) (result RunResult, err error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return result, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return result, err
+	}
+
+	fn(stdout)
+	_, _ = io.Copy(io.Discard, stdout)
+
+	err = cmd.Wait()
+	result.Stderr = cleanString(stderr.String())
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+		err = nil
+	}
+
+	return result, err
+}
+
+// runLines runs the command passing each standard output line with ANSI codes
+// stripped to fn.
+func runLines(cmd *exec.Cmd, fn func(string)) (RunResult, error) {
+	return runCommand(cmd, func(r io.Reader) {
+		scanner := bufio.NewScanner(r)
+		scanner.Split(bufio.ScanLines)
+		for scanner.Scan() {
+			fn(ansiRegex.ReplaceAllString(scanner.Text(), ""))
+		}
+	})
+}
+
+// check returns a ToolError if the run has failed. A zero exit code always
+// succeeds. Otherwise, the run only succeeds when issues have been found and
+// the exit code is one of the codes used to report issues. Any non-zero exit
+// code is accepted when no codes are provided.
+func (r RunResult) check(name string, issues bool, codes ...int) error {
+	if r.ExitCode == 0 {
+		return nil
+	}
+
+	if issues {
+		if len(codes) == 0 {
+			return nil
+		}
+
+		for _, code := range codes {
+			if r.ExitCode == code {
+				return nil
+			}
+		}
+	}
+
+	return &ToolError{
+		Name:     name,
+		ExitCode: r.ExitCode,
+		Stderr:   r.Stderr,
+	}
+}
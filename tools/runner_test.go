@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunResult_check(t *testing.T) {
+	tests := []struct {
+		name     string
+		exitCode int
+		issues   bool
+		codes    []int
+		failed   bool
+	}{
+		{"success", 0, false, nil, false},
+		{"success with issues", 0, true, []int{1}, false},
+		{"failure without issues", 1, false, nil, true},
+		{"failure without issues and codes", 1, false, []int{1}, true},
+		{"issues with any code", 3, true, nil, false},
+		{"issues with an issue code", 1, true, []int{1, 2}, false},
+		{"issues with another issue code", 2, true, []int{1, 2}, false},
+		{"issues with a failure code", 3, true, []int{1, 2}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := RunResult{ExitCode: tt.exitCode, Stderr: "error"}
+			err := r.check("Test", tt.issues, tt.codes...)
+			if !tt.failed {
+				assert.Nil(t, err)
+				return
+			}
+
+			var toolErr *ToolError
+			assert.True(t, errors.As(err, &toolErr))
+			assert.Equal(t, &ToolError{Name: "Test", ExitCode: tt.exitCode, Stderr: "error"}, toolErr)
+		})
+	}
+}
+
+func TestToolError_Error(t *testing.T) {
+	testCases := map[string]string{
+		"":                  "Test failed with exit code 2",
+		"  \n":              "Test failed with exit code 2",
+		"error":             "Test failed with exit code 2: error",
+		"error\nstacktrace": "Test failed with exit code 2: error",
+		"\n  error  \n":     "Test failed with exit code 2: error",
+	}
+
+	for stderr, expected := range testCases {
+		err := &ToolError{Name: "Test", ExitCode: 2, Stderr: stderr}
+		assert.Equalf(t, expected, err.Error(), "Stderr %q", stderr)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	var stdout []byte
+	cmd := exec.Command("sh", "-c", "echo output; printf '\\033[31merror\\033[0m\\n' >&2; exit 3")
+	result, err := runCommand(cmd, func(r io.Reader) {
+		stdout, _ = io.ReadAll(r)
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "output\n", string(stdout))
+	assert.Equal(t, RunResult{ExitCode: 3, Stderr: "error"}, result)
+}
+
+func TestRunCommand_NotExist(t *testing.T) {
+	_, err := runCommand(exec.Command("mod-cli-not-exist"), func(io.Reader) {})
+	assert.NotNil(t, err)
+}
+
+func TestRunLines(t *testing.T) {
+	var lines []string
+	cmd := exec.Command("sh", "-c", "printf 'a\\n\\033[1mb\\033[0m\\n'")
+	result, err := runLines(cmd, func(line string) {
+		lines = append(lines, line)
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, lines)
+	assert.Equal(t, RunResult{}, result)
+}
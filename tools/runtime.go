@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// Runtime represents a container runtime with a Docker-compatible CLI.
+type Runtime int
+
+const (
+	// RuntimeDocker represents Docker.
+	RuntimeDocker Runtime = iota
+
+	// RuntimePodman represents Podman.
+	RuntimePodman
+
+	// RuntimeNerdctl represents nerdctl for containerd.
+	RuntimeNerdctl
+)
+
+// Runtimes holds all supported runtime commands.
+var Runtimes = []string{"docker", "podman", "nerdctl"}
+
+var runtimeNames = []string{"Docker", "Podman", "nerdctl"}
+
+// ParseRuntime parses a runtime command like "podman".
+func ParseRuntime(name string) (Runtime, error) {
+	for i, n := range Runtimes {
+		if n == name {
+			return Runtime(i), nil
+		}
+	}
+	return RuntimeDocker, fmt.Errorf("unknown container runtime: %s", name)
+}
+
+// DetectRuntime returns the first runtime available on the system checking
+// them in the order of Runtimes.
+func DetectRuntime() (Runtime, bool) {
+	for i, name := range Runtimes {
+		if _, err := exec.LookPath(name); err == nil {
+			return Runtime(i), true
+		}
+	}
+	return RuntimeDocker, false
+}
+
+// String returns a runtime command like "podman".
+func (r Runtime) String() string {
+	if int(r) < len(Runtimes) {
+		return Runtimes[r]
+	}
+	return ""
+}
+
+// Name returns a runtime name like "Podman".
+func (r Runtime) Name() string {
+	if int(r) < len(runtimeNames) {
+		return runtimeNames[r]
+	}
+	return ""
+}
+
+// volumeOptions returns options appended to the volume flag. The "z" option
+// relabels the volume for SELinux which nerdctl doesn't support.
+func (r Runtime) volumeOptions() string {
+	if r == RuntimeNerdctl {
+		return ""
+	}
+	return "z"
+}
+
+// rootlessFlags returns flags to run a container as the current user in
+// rootless mode. Rootless Docker and nerdctl already map the container root to
+// the current user, while Podman needs to keep the user namespace.
+func (r Runtime) rootlessFlags() []string {
+	if r == RuntimePodman {
+		return []string{"--userns=keep-id"}
+	}
+	return nil
+}
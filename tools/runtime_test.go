@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRuntimes sets PATH for the rest of the test to a directory with only the
+// provided runtime commands.
+func fakeRuntimes(t *testing.T, names ...string) {
+	dir := t.TempDir()
+	for _, name := range names {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0o755))
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestParseRuntime(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Runtime
+		title    string
+	}{
+		{"docker", RuntimeDocker, "Docker"},
+		{"podman", RuntimePodman, "Podman"},
+		{"nerdctl", RuntimeNerdctl, "nerdctl"},
+	}
+
+	for _, tt := range tests {
+		r, err := ParseRuntime(tt.name)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.expected, r)
+		assert.Equal(t, tt.name, r.String())
+		assert.Equal(t, tt.title, r.Name())
+	}
+
+	_, err := ParseRuntime("lxc")
+	assert.EqualError(t, err, "unknown container runtime: lxc")
+
+	assert.Equal(t, "", Runtime(len(Runtimes)).String())
+	assert.Equal(t, "", Runtime(len(Runtimes)).Name())
+}
+
+func TestDetectRuntime(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		expected Runtime
+		ok       bool
+	}{
+		{"none", nil, RuntimeDocker, false},
+		{"docker", []string{"docker"}, RuntimeDocker, true},
+		{"podman", []string{"podman"}, RuntimePodman, true},
+		{"nerdctl", []string{"nerdctl"}, RuntimeNerdctl, true},
+		{"docker first", []string{"nerdctl", "podman", "docker"}, RuntimeDocker, true},
+		{"podman before nerdctl", []string{"nerdctl", "podman"}, RuntimePodman, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeRuntimes(t, tt.commands...)
+			r, ok := DetectRuntime()
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, r)
+		})
+	}
+}
+
+func TestDockerized_runArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		runtime  Runtime
+		rootless bool
+		expected []string
+	}{
+		{
+			"docker",
+			RuntimeDocker,
+			false,
+			[]string{"run", "--rm", "-u", "dst-mod", "-v", "/src/mod:/opt/mod:z", "-w", "/opt/mod", "image"},
+		},
+		{
+			"docker rootless",
+			RuntimeDocker,
+			true,
+			[]string{"run", "--rm", "-v", "/src/mod:/opt/mod:z", "-w", "/opt/mod", "image"},
+		},
+		{
+			"podman",
+			RuntimePodman,
+			false,
+			[]string{"run", "--rm", "-u", "dst-mod", "-v", "/src/mod:/opt/mod:z", "-w", "/opt/mod", "image"},
+		},
+		{
+			"podman rootless",
+			RuntimePodman,
+			true,
+			[]string{"run", "--rm", "--userns=keep-id", "-v", "/src/mod:/opt/mod:z", "-w", "/opt/mod", "image"},
+		},
+		{
+			"nerdctl",
+			RuntimeNerdctl,
+			false,
+			[]string{"run", "--rm", "-u", "dst-mod", "-v", "/src/mod:/opt/mod", "-w", "/opt/mod", "image"},
+		},
+		{
+			"nerdctl rootless",
+			RuntimeNerdctl,
+			true,
+			[]string{"run", "--rm", "-v", "/src/mod:/opt/mod", "-w", "/opt/mod", "image"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDockerized()
+			d.Image = "image"
+			d.Volume = "/src/mod"
+			d.Runtime = tt.runtime
+			d.Rootless = tt.rootless
+
+			args, err := d.PrepareArgs()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, args)
+			assert.Equal(t, tt.expected, d.Args())
+			assert.Equal(t, tt.runtime.String(), d.command().Args[0])
+		})
+	}
+}
+
+func TestDockerized_runArgs_Settings(t *testing.T) {
+	d := NewDockerized()
+	d.Image = "image"
+	d.Volume = "/src/mod"
+	d.Remove = false
+	d.User = ""
+	d.Mounts = []string{"/cache:/cache:ro"}
+	d.Env = map[string]string{"LUA_PATH": "./?.lua", "CI": "true"}
+	d.Network = "none"
+	d.ExtraArgs = []string{"--cpus", "2"}
+
+	assert.Equal(t, []string{
+		"run",
+		"-v", "/src/mod:/opt/mod:z",
+		"-w", "/opt/mod",
+		"-v", "/cache:/cache:ro",
+		"-e", "CI=true",
+		"-e", "LUA_PATH=./?.lua",
+		"--network", "none",
+		"--cpus", "2",
+		"image",
+	}, d.runArgs())
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"errors"
+	"os"
+)
+
+// SteamCMD represents a SteamCMD tool.
+type SteamCMD struct {
+	Tool
+}
+
+func init() {
+	Register(Registration{
+		ID: "steamcmd",
+		New: func() (Tooler, error) {
+			return NewSteamCMD()
+		},
+	})
+}
+
+// NewSteamCMD creates a new SteamCMD instance.
+func NewSteamCMD() (*SteamCMD, error) {
+	tool, err := NewTool("SteamCMD", "steamcmd")
+	if err != nil {
+		return nil, err
+	}
+	return &SteamCMD{
+		Tool: *tool,
+	}, nil
+}
+
+func (s *SteamCMD) parseVersion(_ string) (string, error) {
+	return "", errors.New("not supported")
+}
+
+// LoadVersion doesn't load anything as SteamCMD can't report its version
+// without updating itself first.
+func (s *SteamCMD) LoadVersion() (string, error) {
+	return s.parseVersion("")
+}
+
+// BuildWorkshopItem builds and uploads a Steam Workshop item described in the
+// provided VDF file. The output is passed through as SteamCMD may ask for a
+// password or a Steam Guard code.
+func (s *SteamCMD) BuildWorkshopItem(username, vdf string) error {
+	cmd := s.ExecCommand(
+		"+login",
+		username,
+		"+workshop_build_item",
+		vdf,
+		"+quit",
+	)
+
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
@@ -1,7 +1,6 @@
 package tools
 
 import (
-	"bufio"
 	"bytes"
 	"errors"
 	"io"
@@ -16,6 +15,17 @@ type StyLua struct {
 	DefaultExt []string
 }
 
+func init() {
+	Register(Registration{
+		ID:           "stylua",
+		Capabilities: CapabilityFormat,
+		Dockerized:   true,
+		New: func() (Tooler, error) {
+			return NewStyLua()
+		},
+	})
+}
+
 // NewStyLua creates a new StyLua instance.
 func NewStyLua() (*StyLua, error) {
 	tool, err := NewTool("StyLua", "stylua")
@@ -37,10 +47,6 @@ func (s *StyLua) parseVersion(str string) (string, error) {
 }
 
 func (s *StyLua) prepareArg(write bool, files ...string) []string {
-	if len(files) == 0 {
-		f, _, _ := s.workingDir.ListFiles(s.DefaultExt...)
-		files = append(files, f...)
-	}
 	var a []string
 	if !write {
 		a = append(a, "-c")
@@ -78,20 +84,8 @@ func (s *StyLua) LoadVersion() (string, error) {
 	return ver, nil
 }
 
-// Check checks formatting in the provided files.
-func (s *StyLua) Check(arg ...string) (result Format, err error) {
-	cmd := s.ExecCommand(s.prepareArg(false, arg...)...)
-	stdout, _ := cmd.StdoutPipe()
-
-	if err := cmd.Start(); err != nil {
-		return result, err
-	}
-
-	scanner := bufio.NewScanner(stdout)
-	scanner.Split(bufio.ScanLines)
-	for scanner.Scan() {
-		line := scanner.Text()
-		line = ansiRegex.ReplaceAllString(line, "")
+func (s *StyLua) check(files []string) (result Format, err error) {
+	run, err := runLines(s.ExecCommand(s.prepareArg(false, files...)...), func(line string) {
 		if strings.HasPrefix(line, "Diff in ") {
 			str := strings.TrimPrefix(line, "Diff in ")
 			str = strings.TrimSuffix(str, ":")
@@ -100,23 +94,28 @@ func (s *StyLua) Check(arg ...string) (result Format, err error) {
 				State: FileStateWarning,
 			})
 		}
+	})
+	if err != nil {
+		return result, err
 	}
 
-	_ = cmd.Wait()
-
-	return result, nil
+	result.RunResult = run
+	return result, run.check(s.Name(), result.HasIssues(), 1)
 }
 
-// Fix fixes formatting in the provided files.
-func (s *StyLua) Fix(arg ...string) (result Format, err error) {
-	result, err = s.Check(arg...)
+func (s *StyLua) fix(files []string) (result Format, err error) {
+	result, err = s.check(files)
 	if err != nil {
 		return result, err
 	}
 
-	cmd := s.ExecCommand(s.prepareArg(true, arg...)...)
+	run, err := runLines(s.ExecCommand(s.prepareArg(true, files...)...), func(string) {})
+	if err != nil {
+		return result, err
+	}
 
-	if err := cmd.Start(); err != nil {
+	result.RunResult = run
+	if err := run.check(s.Name(), false); err != nil {
 		return result, err
 	}
 
@@ -124,7 +123,39 @@ func (s *StyLua) Fix(arg ...string) (result Format, err error) {
 		result.Files[i].State = FileStateSuccess
 	}
 
-	_ = cmd.Wait()
-
 	return result, nil
 }
+
+// Check checks formatting in the provided files. Exit code 1 is used to report
+// unformatted files, but StyLua also uses it for errors, so it's only treated as
+// issues when at least one file has been reported.
+func (s *StyLua) Check(arg ...string) (result Format, err error) {
+	files, err := s.files(arg, s.DefaultExt...)
+	if err != nil {
+		return result, err
+	}
+
+	err = eachBatch(files, func(batch []string) error {
+		format, err := s.check(batch)
+		result.merge(format)
+		return err
+	})
+
+	return result, err
+}
+
+// Fix fixes formatting in the provided files.
+func (s *StyLua) Fix(arg ...string) (result Format, err error) {
+	files, err := s.files(arg, s.DefaultExt...)
+	if err != nil {
+		return result, err
+	}
+
+	err = eachBatch(files, func(batch []string) error {
+		format, err := s.fix(batch)
+		result.merge(format)
+		return err
+	})
+
+	return result, err
+}
@@ -19,6 +19,8 @@ type Tooler interface {
 	SetDockerized(*Dockerized) error
 	DockerImage() string
 	SetDockerImage(image string)
+	Dockerized() *Dockerized
+	SetContainer(*Container)
 	IsDockerImageAvailable() bool
 	PullDockerImage() bool
 	SetIgnore([]string)
@@ -38,6 +40,7 @@ type Tool struct {
 	// CmdArgs holds a command arguments.
 	CmdArgs []string
 
+	container   *Container
 	dockerized  *Dockerized
 	name        string
 	path        string
@@ -49,6 +52,17 @@ type Tool struct {
 // Format represents a formatting result.
 type Format struct {
 	Files []FormatFile
+	RunResult
+}
+
+// HasIssues checks if any of the files still has formatting issues.
+func (f Format) HasIssues() bool {
+	for _, file := range f.Files {
+		if file.State == FileStateWarning {
+			return true
+		}
+	}
+	return false
 }
 
 // FormatFile represents a single formatting file.
@@ -64,6 +78,12 @@ type FormatFile struct {
 type Lint struct {
 	Files  []LintFile
 	Stdout string
+	RunResult
+}
+
+// HasIssues checks if any issues have been found.
+func (l Lint) HasIssues() bool {
+	return len(l.Files) > 0
 }
 
 // LintFile represents a single linting file.
@@ -134,8 +154,11 @@ func (t *Tool) exec(name string, arg ...string) *exec.Cmd {
 
 func (t *Tool) execDocker(arg ...string) *exec.Cmd {
 	a := t.dockerized.Args()
+	if t.container != nil && t.container.Start() == nil {
+		a = t.container.ExecArgs()
+	}
 	a = append(a, arg...)
-	return exec.Command("docker", a...)
+	return t.dockerized.command(a...)
 }
 
 // Name returns a name of the tool.
@@ -160,6 +183,17 @@ func (t *Tool) SetDockerized(dockerized *Dockerized) error {
 	return err
 }
 
+// Dockerized gets the Docker arguments.
+func (t *Tool) Dockerized() *Dockerized {
+	return t.dockerized
+}
+
+// SetContainer sets a long-lived container to run the tool in through Docker
+// instead of starting a new one each time. Nil restores the default behaviour.
+func (t *Tool) SetContainer(container *Container) {
+	t.container = container
+}
+
 // DockerImage gets the Docker image.
 func (t *Tool) DockerImage() string {
 	return t.dockerized.Image
@@ -168,6 +202,7 @@ func (t *Tool) DockerImage() string {
 // SetDockerImage sets the Docker image.
 func (t *Tool) SetDockerImage(image string) {
 	t.dockerized.Image = image
+	_, _ = t.dockerized.PrepareArgs()
 }
 
 // IsDockerImageAvailable checks if the Docker image is available.
@@ -185,6 +220,17 @@ func (t *Tool) SetIgnore(ignore []string) {
 	t.workingDir.SetIgnore(ignore)
 }
 
+// files returns files to pass to the tool based on the ignore list. When no
+// paths are provided, all files with the provided extensions are listed.
+// Otherwise, the provided files, directories and globs are expanded.
+func (t *Tool) files(paths []string, ext ...string) ([]string, error) {
+	if len(paths) == 0 {
+		files, _, err := t.workingDir.ListFiles(ext...)
+		return files, err
+	}
+	return t.workingDir.ExpandPaths(paths, ext...)
+}
+
 // SetRunInDocker sets whether a tool should be run in Docker.
 func (t *Tool) SetRunInDocker(runInDocker bool) {
 	t.runInDocker = runInDocker
@@ -3,8 +3,13 @@
 package tools
 
 import (
+	"errors"
+	"fmt"
 	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"time"
 )
 
 // Taken from: https://github.com/acarl005/stripansi
@@ -36,105 +41,195 @@ func cleanString(str string) string {
 
 // Controller is the interface that wraps the Tools methods.
 type Controller interface {
+	Add(Registration) error
+	Get(string) Tooler
+	Registration(string) (Registration, bool)
+	IDs() []string
+	SetJobs(int)
+	Jobs() int
+	Each([]string, func(int, string, Tooler))
+	WithCapability(Capability) []string
 	SetToolsRunInDocker(bool)
+	SetRuntime(Runtime, bool)
+	SetContainerMode(ContainerMode, time.Duration)
+	Close() error
 	LookPaths()
 	LoadVersions()
 }
 
-// Tools represents all the supported tools.
+// Tools represents all registered tools.
 type Tools struct {
-	Busted   *Busted
-	Docker   *Docker
-	Krane    *Krane
-	Ktech    *Ktech
-	LDoc     *LDoc
-	Luacheck *Luacheck
-	Prettier *Prettier
-	StyLua   *StyLua
-	all      []Tooler
-}
-
-// New creates a new Tools instance.
+	// Docker holds the Docker tool used to run other tools in containers.
+	Docker *Docker
+
+	containers    map[string]*Container
+	ids           []string
+	jobs          int
+	registrations map[string]Registration
+	tools         map[string]Tooler
+}
+
+// New creates a new Tools instance with all tools registered using Register.
 func New() (*Tools, error) {
-	busted, err := NewBusted()
-	if err != nil {
-		return nil, err
+	t := &Tools{
+		containers:    map[string]*Container{},
+		jobs:          runtime.NumCPU(),
+		registrations: map[string]Registration{},
+		tools:         map[string]Tooler{},
 	}
 
-	docker, err := NewDocker()
-	if err != nil {
-		return nil, err
+	for _, r := range Registrations() {
+		if err := t.Add(r); err != nil {
+			return nil, err
+		}
 	}
 
-	krane, err := NewKrane()
-	if err != nil {
-		return nil, err
+	docker, ok := t.Get("docker").(*Docker)
+	if !ok {
+		return nil, errors.New("docker is not registered")
 	}
+	t.Docker = docker
 
-	ktech, err := NewKtech()
-	if err != nil {
-		return nil, err
-	}
+	return t, nil
+}
 
-	ldoc, err := NewLDoc()
-	if err != nil {
-		return nil, err
+// Add creates a tool from the provided registration and adds it. Unlike
+// Register, it only affects this Tools instance, which is useful for
+// user-defined tools.
+func (t *Tools) Add(r Registration) error {
+	if _, ok := t.tools[r.ID]; ok {
+		return fmt.Errorf("tool %s already exists", r.ID)
 	}
 
-	luacheck, err := NewLuacheck()
+	tool, err := r.New()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	prettier, err := NewPrettier()
-	if err != nil {
-		return nil, err
+	if len(r.DockerImage) > 0 {
+		tool.SetDockerImage(r.DockerImage)
 	}
 
-	stylua, err := NewStyLua()
-	if err != nil {
-		return nil, err
+	t.ids = append(t.ids, r.ID)
+	t.registrations[r.ID] = r
+	t.tools[r.ID] = tool
+
+	return nil
+}
+
+// Get gets a tool by its ID or nil if it doesn't exist.
+func (t *Tools) Get(id string) Tooler {
+	return t.tools[id]
+}
+
+// Registration gets a registration of a tool by its ID.
+func (t *Tools) Registration(id string) (Registration, bool) {
+	r, ok := t.registrations[id]
+	return r, ok
+}
+
+// IDs gets IDs of all tools in the order they have been added.
+func (t *Tools) IDs() []string {
+	return t.ids
+}
+
+// Images returns sorted images of all tools which can be run in Docker. Each
+// image is listed only once.
+func (t *Tools) Images() (result []string) {
+	seen := map[string]bool{}
+	for _, id := range t.ids {
+		r, ok := t.registrations[id]
+		if !ok || !r.Dockerized {
+			continue
+		}
+
+		image := t.tools[id].DockerImage()
+		if len(image) > 0 && !seen[image] {
+			seen[image] = true
+			result = append(result, image)
+		}
 	}
+	sort.Strings(result)
+	return result
+}
 
-	return &Tools{
-		Busted:   busted,
-		Docker:   docker,
-		Krane:    krane,
-		Ktech:    ktech,
-		LDoc:     ldoc,
-		Luacheck: luacheck,
-		Prettier: prettier,
-		StyLua:   stylua,
-		all: []Tooler{
-			busted,
-			docker,
-			krane,
-			ktech,
-			ldoc,
-			luacheck,
-			prettier,
-			stylua,
-		},
-	}, nil
+// WithCapability gets IDs of all tools with the provided capability in the
+// order they have been added.
+func (t *Tools) WithCapability(c Capability) (result []string) {
+	for _, id := range t.ids {
+		if t.registrations[id].Capabilities.Has(c) {
+			result = append(result, id)
+		}
+	}
+	return result
 }
 
 // SetToolsRunInDocker sets all tools to be run in Docker.
 func (t *Tools) SetToolsRunInDocker(runInDocker bool) {
-	for _, tool := range t.all {
-		tool.SetRunInDocker(runInDocker)
+	for _, id := range t.ids {
+		t.tools[id].SetRunInDocker(runInDocker)
+	}
+}
+
+// SetRuntime sets a container runtime for all tools and whether it runs in
+// rootless mode. It should be called before SetContainerMode.
+func (t *Tools) SetRuntime(runtime Runtime, rootless bool) {
+	t.Docker.SetRuntime(runtime)
+	for _, id := range t.ids {
+		d := t.tools[id].Dockerized()
+		d.Runtime = runtime
+		d.Rootless = rootless
+		_, _ = d.PrepareArgs()
 	}
 }
 
-// LookPaths looks for paths of all tools.
+// SetContainerMode sets all tools to be run in long-lived containers when run
+// in Docker. Tools with the same Dockerized settings share a single container.
+// Close should be called once the tools are no longer needed.
+func (t *Tools) SetContainerMode(mode ContainerMode, idleTimeout time.Duration) {
+	for _, id := range t.ids {
+		tool := t.tools[id]
+
+		if mode == ContainerModeNone {
+			tool.SetContainer(nil)
+			continue
+		}
+
+		key := strings.Join(tool.Dockerized().runArgs(), " ")
+		c, ok := t.containers[key]
+		if !ok {
+			c = NewContainer(mode, tool.Dockerized())
+			if idleTimeout > 0 {
+				c.IdleTimeout = idleTimeout
+			}
+			t.containers[key] = c
+		}
+
+		tool.SetContainer(c)
+	}
+}
+
+// Close closes all containers started for ContainerModeSession or
+// ContainerModeProject.
+func (t *Tools) Close() (err error) {
+	for _, c := range t.containers {
+		if cerr := c.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// LookPaths looks for paths of all tools in parallel.
 func (t *Tools) LookPaths() {
-	for _, tool := range t.all {
+	t.Each(t.ids, func(_ int, _ string, tool Tooler) {
 		_, _ = tool.LookPath()
-	}
+	})
 }
 
-// LoadVersions loads versions of all tools.
+// LoadVersions loads versions of all tools in parallel.
 func (t *Tools) LoadVersions() {
-	for _, tool := range t.all {
+	t.Each(t.ids, func(_ int, _ string, tool Tooler) {
 		_, _ = tool.LoadVersion()
-	}
+	})
 }
@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver"
+)
+
+// ParseConstraint parses a version constraint like ">=0.11, <0.12" or a hyphen
+// range like "0.11 - 0.12". Alternatives can be combined using "||".
+func ParseConstraint(constraint string) (*semver.Constraints, error) {
+	return semver.NewConstraint(constraint)
+}
+
+// ParseVersion parses a semantic version from a version string loaded by
+// LoadVersion like "Luacheck: 0.26.0" or "2.5.1-abc". Anything after the patch
+// version is dropped as tools like Docker append a commit hash there, which
+// would otherwise be treated as a prerelease not satisfying most constraints.
+func ParseVersion(str string) (*semver.Version, error) {
+	match := versionRegex.FindString(cleanString(str))
+	if len(match) == 0 {
+		return nil, fmt.Errorf("no version in %q", str)
+	}
+
+	ver, err := semver.NewVersion(match)
+	if err != nil {
+		return nil, err
+	}
+
+	return semver.NewVersion(fmt.Sprintf("%d.%d.%d", ver.Major(), ver.Minor(), ver.Patch()))
+}
+
+// CheckVersion checks if the tool version satisfies the provided constraint
+// like ">=0.11, <0.12". The version is loaded first unless already loaded.
+func CheckVersion(tool Tooler, constraint string) error {
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return fmt.Errorf("invalid %s version constraint %q: %w", tool.Name(), constraint, err)
+	}
+
+	str := tool.Version()
+	if len(str) == 0 {
+		str, err = tool.LoadVersion()
+		if err != nil {
+			return fmt.Errorf("failed to load %s version: %w", tool.Name(), err)
+		}
+	}
+
+	ver, err := ParseVersion(str)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s version: %w", tool.Name(), err)
+	}
+
+	if !c.Check(ver) {
+		return fmt.Errorf("%s %s doesn't satisfy the required version %s", tool.Name(), ver, constraint)
+	}
+
+	return nil
+}
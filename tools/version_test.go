@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		valid      []string
+		invalid    []string
+	}{
+		{"^0.26", []string{"0.26.0", "0.99.1"}, []string{"0.25.9", "1.0.0"}},
+		{">=0.11, <0.12", []string{"0.11.0", "0.11.9"}, []string{"0.10.0", "0.12.0"}},
+		{">= 0.11", []string{"0.11.0", "20.10.7"}, []string{"0.10.9"}},
+		{"1.2 - 1.4", []string{"1.2.0", "1.3.5", "1.4.0"}, []string{"1.1.9", "1.4.1"}},
+		{"1.2 - 1.4 || >=2", []string{"1.3.0", "2.0.0"}, []string{"1.5.0"}},
+		{"~0.26 || ~0.28", []string{"0.26.1", "0.28.0"}, []string{"0.27.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			c, err := ParseConstraint(tt.constraint)
+			if !assert.Nil(t, err) {
+				return
+			}
+
+			for _, str := range tt.valid {
+				ver, err := ParseVersion(str)
+				assert.Nil(t, err)
+				assert.Truef(t, c.Check(ver), "%s should satisfy", str)
+			}
+
+			for _, str := range tt.invalid {
+				ver, err := ParseVersion(str)
+				assert.Nil(t, err)
+				assert.Falsef(t, c.Check(ver), "%s shouldn't satisfy", str)
+			}
+		})
+	}
+}
+
+func TestParseConstraint_Invalid(t *testing.T) {
+	for _, constraint := range []string{"", "latest", ">=0.11 <0.12"} {
+		_, err := ParseConstraint(constraint)
+		assert.NotNilf(t, err, "Constraint %q", constraint)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		str      string
+		expected string
+	}{
+		{"0.26.0", "0.26.0"},
+		{"v1.2.3", "1.2.3"},
+		{"Luacheck: 0.26.0", "0.26.0"},
+		{"stylua 0.11.2\n", "0.11.2"},
+		{"\x1b[32m2.5.1\x1b[0m", "2.5.1"},
+		{"2.5.1-abc", "2.5.1"},
+		{"20.10.7-f0df350", "20.10.7"},
+		{"1.0.0-beta.1+build.5", "1.0.0"},
+		{"1.2", "1.2.0"},
+		{"5", "5.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			ver, err := ParseVersion(tt.str)
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.expected, ver.String())
+			}
+		})
+	}
+}
+
+func TestParseVersion_Invalid(t *testing.T) {
+	for _, str := range []string{"", "unknown", "\x1b[32m\x1b[0m"} {
+		_, err := ParseVersion(str)
+		assert.NotNilf(t, err, "Version %q", str)
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    string
+		constraint string
+		expected   string
+	}{
+		{"satisfied", "0.11.2", ">=0.11, <0.12", ""},
+		{"hyphen range", "1.3.0", "1.2 - 1.4", ""},
+		{"commit hash", "20.10.7-f0df350", ">=0.11", ""},
+		{"not satisfied", "0.12.0", ">=0.11, <0.12", "Test 0.12.0 doesn't satisfy the required version >=0.11, <0.12"},
+		{"invalid constraint", "0.11.2", "latest", `invalid Test version constraint "latest": `},
+		{"invalid version", "unknown", ">=0.11", `failed to parse Test version: no version in "unknown"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCustom("Test", "sh")
+			assert.Nil(t, err)
+			c.version = tt.version
+
+			err = CheckVersion(c, tt.constraint)
+			if len(tt.expected) == 0 {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckVersion_Load(t *testing.T) {
+	c, err := NewCustom("Test", "sh")
+	assert.Nil(t, err)
+	c.VersionArgs = []string{"-c", "echo 'Test v0.26.0'"}
+
+	assert.Nil(t, CheckVersion(c, "^0.26"))
+	assert.Equal(t, "v0.26.0", c.Version())
+
+	c, err = NewCustom("Test", "sh")
+	assert.Nil(t, err)
+	c.VersionArgs = []string{"-c", "exit 1"}
+
+	err = CheckVersion(c, "^0.26")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "failed to load Test version")
+	}
+}
@@ -1,21 +1,47 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
 	"path"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/dstmodders/mod-cli/changelog"
+	"github.com/dstmodders/mod-cli/tools"
 	"github.com/dstmodders/mod-cli/workshop"
+	"github.com/fatih/color"
 	"github.com/manifoldco/promptui"
 )
 
+var (
+	errWorkshopCanceled   = errors.New("canceled")
+	errWorkshopDestExists = errors.New("destination already exists")
+	errWorkshopValidation = errors.New("validation failed")
+	errWorkshopSizeBudget = errors.New("size budget exceeded")
+	errWorkshopVersion    = errors.New("unsupported tool version")
+)
+
 type Workshop struct {
-	cfg      *Config
-	destName string
-	list     bool
-	mod      *Mod
-	path     string
-	workshop *workshop.Workshop
-	zip      bool
+	analyze     bool
+	cfg         *Config
+	changeNote  string
+	destName    string
+	dryRun      bool
+	install     bool
+	list        bool
+	mod         *Mod
+	noOverwrite bool
+	path        string
+	publish     bool
+	size        bool
+	stdin       io.ReadCloser
+	workshop    *workshop.Workshop
+	yes         bool
 }
 
 func NewWorkshop(cfg *Config) *Workshop {
@@ -33,6 +59,10 @@ func (w *Workshop) printInfo() {
 func (w *Workshop) printPaths() {
 	printTitle("Paths")
 	printNameValue("Source", w.workshop.AbsSrcPath())
+	if w.workshop.Format().IsArchive() {
+		printNameValue("Destination", w.workshop.ArchivePath())
+		return
+	}
 	printNameValue("Destination", w.workshop.AbsDestPath())
 }
 
@@ -52,57 +82,924 @@ func (w *Workshop) printDefault() error {
 	return nil
 }
 
-func (w *Workshop) copy() error {
-	total, err := w.workshop.CountDestItems()
+// modPath resolves a path from the configuration against the mod directory
+// unless it's absolute.
+func (w *Workshop) modPath(p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(w.mod.pathAbs, p)
+}
+
+// workingPath returns the path relative to the working directory when possible
+// as tools run through Docker only have access to it.
+func workingPath(p string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return p
+	}
+
+	if rel, err := filepath.Rel(wd, p); err == nil {
+		return rel
+	}
+
+	return p
+}
+
+func (w *Workshop) compileAsset(ktech *tools.Ktech, png string) (bool, error) {
+	cfg := w.cfg.Workshop.Assets
+
+	rel, err := filepath.Rel(w.modPath(cfg.Src), png)
+	if err != nil {
+		return false, err
+	}
+
+	name := filepath.Join(cfg.Dest, strings.TrimSuffix(rel, filepath.Ext(rel)))
+	tex := filepath.Join(w.modPath(cfg.Cache), name+".tex")
+	atlas := filepath.Join(w.modPath(cfg.Cache), name+".xml")
+
+	isCompiled := func(path string, src os.FileInfo) bool {
+		stat, err := os.Stat(path)
+		return err == nil && !stat.ModTime().Before(src.ModTime())
+	}
+
+	stat, err := os.Stat(png)
+	if err != nil {
+		return false, err
+	}
+
+	compiled := false
+	if !isCompiled(tex, stat) || !isCompiled(atlas, stat) {
+		if err := os.MkdirAll(filepath.Dir(tex), os.ModePerm); err != nil {
+			return false, err
+		}
+
+		if err := ktech.Convert(workingPath(png), workingPath(tex), workingPath(atlas)); err != nil {
+			return false, err
+		}
+
+		compiled = true
+	}
+
+	if err := w.workshop.AddFile(name+".tex", tex); err != nil {
+		return false, err
+	}
+
+	if err := w.workshop.AddFile(name+".xml", atlas); err != nil {
+		return false, err
+	}
+
+	return compiled, nil
+}
+
+// findAssets returns paths of all PNG images in the assets source directory.
+func findAssets(src string) (pngs []string, err error) {
+	err = filepath.Walk(src, func(p string, i os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !i.IsDir() && strings.ToLower(filepath.Ext(p)) == ".png" {
+			pngs = append(pngs, p)
+		}
+
+		return nil
+	})
+	return pngs, err
+}
+
+// newKtech creates ktech and checks if it can be run.
+func (w *Workshop) newKtech() (*tools.Ktech, error) {
+	t, err := newTools(w.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ktech, ok := t.Get("ktech").(*tools.Ktech)
+	if !ok {
+		return nil, errors.New("ktech is not registered")
+	}
+
+	if err := checkIfToolExists(t.Docker, ktech); err != nil {
+		return nil, err
+	}
+
+	if err := checkToolVersion(w.cfg, "ktech", ktech); err != nil {
+		return nil, fmt.Errorf("%w: %v", errWorkshopVersion, err)
+	}
+
+	return ktech, nil
+}
+
+// compileAssets compiles assets and adds them to the files. Nothing is printed
+// when only listing files.
+func (w *Workshop) compileAssets() error {
+	cfg := w.cfg.Workshop.Assets
+	if len(cfg.Src) == 0 {
+		return nil
+	}
+
+	pngs, err := findAssets(w.modPath(cfg.Src))
+	if err != nil || len(pngs) == 0 {
+		return err
+	}
+
+	ktech, err := w.newKtech()
+	if err != nil {
+		return err
+	}
+
+	if !w.list {
+		printTitle(fmt.Sprintf("Assets | Total: %d", len(pngs)))
+	}
+
+	for _, png := range pngs {
+		compiled, err := w.compileAsset(ktech, png)
+		if err != nil {
+			return err
+		}
+
+		if w.list {
+			continue
+		}
+
+		state := "cached"
+		if compiled {
+			state = color.GreenString("compiled")
+		}
+
+		fmt.Printf("%s %s\n", state, workingPath(png))
+	}
+
+	if !w.list {
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func (w *Workshop) printAnalysis() error {
+	analysis, err := w.workshop.Analyze(w.mod.modinfo)
 	if err != nil {
 		return err
 	}
 
-	if total > 0 {
-		prompt := promptui.Prompt{
-			Label:     "Destination directory already exists. Override",
-			Default:   "y",
-			IsConfirm: true,
+	printTitle(fmt.Sprintf("Referenced But Excluded | Total: %d", len(analysis.Excluded)))
+	for _, ref := range analysis.Excluded {
+		fmt.Printf(
+			"%s %s %s\n",
+			color.YellowString("warning"),
+			ref.Path,
+			color.YellowString("(%s %q in %s)", ref.Kind, ref.Name, ref.File),
+		)
+	}
+
+	fmt.Println()
+	printTitle(fmt.Sprintf("Included But Unreferenced | Total: %d", len(analysis.Unreferenced)))
+	for _, file := range analysis.Unreferenced {
+		fmt.Println(file)
+	}
+
+	return nil
+}
+
+func printSizeEntries(entries []workshop.SizeEntry, names []string, showFiles bool) {
+	width := 0
+	for _, name := range names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+
+	for i, entry := range entries {
+		line := fmt.Sprintf("%-*s  %10s", width, names[i], workshop.FormatSize(entry.Size))
+		if showFiles {
+			files := "files"
+			if entry.Files == 1 {
+				files = "file"
+			}
+			line += color.New(color.FgHiBlack).Sprintf("  %d %s", entry.Files, files)
 		}
+		fmt.Println(line)
+	}
+}
+
+func sizeTreeNames(dirs []workshop.SizeEntry) []string {
+	names := make([]string, len(dirs))
+	isLast := map[int]bool{}
 
-		_, err := prompt.Run()
+	for i, dir := range dirs {
+		if dir.Depth == 0 {
+			names[i] = dir.Name
+			continue
+		}
+
+		last := true
+		for _, next := range dirs[i+1:] {
+			if next.Depth < dir.Depth {
+				break
+			}
+			if next.Depth == dir.Depth {
+				last = false
+				break
+			}
+		}
+		isLast[dir.Depth] = last
+
+		var prefix strings.Builder
+		for depth := 1; depth < dir.Depth; depth++ {
+			if isLast[depth] {
+				prefix.WriteString("    ")
+				continue
+			}
+			prefix.WriteString("│   ")
+		}
+
+		if last {
+			prefix.WriteString("└── ")
+		} else {
+			prefix.WriteString("├── ")
+		}
+
+		names[i] = prefix.String() + filepath.Base(dir.Name)
+	}
+
+	return names
+}
+
+func (w *Workshop) printSize() error {
+	report, err := w.workshop.Size(10)
+	if err != nil {
+		return err
+	}
+
+	printTitle("Size")
+	printNameValue("Total", fmt.Sprintf("%s (%d bytes)", workshop.FormatSize(report.Total), report.Total))
+	printNameValue("Compressed", fmt.Sprintf("~%s", workshop.FormatSize(report.Compressed)))
+
+	budget := w.workshop.MaxSize()
+	if budget > 0 {
+		printNameValue("Budget", fmt.Sprintf(
+			"%s (%.1f%% used)",
+			workshop.FormatSize(budget),
+			float64(report.Total)/float64(budget)*100,
+		))
+	}
+	fmt.Println()
+
+	printTitle("Directories")
+	printSizeEntries(report.Dirs, sizeTreeNames(report.Dirs), true)
+	fmt.Println()
+
+	exts := make([]string, len(report.Exts))
+	for i, ext := range report.Exts {
+		exts[i] = ext.Name
+	}
+
+	printTitle("Extensions")
+	printSizeEntries(report.Exts, exts, true)
+	fmt.Println()
+
+	largest := make([]string, len(report.Largest))
+	for i, file := range report.Largest {
+		largest[i] = file.Name
+	}
+
+	printTitle(fmt.Sprintf("Largest Files | Total: %d", len(report.Largest)))
+	printSizeEntries(report.Largest, largest, false)
+
+	if budget > 0 && report.Total > budget {
+		fmt.Println()
+		return fmt.Errorf(
+			"%w: %s is over the budget of %s by %s",
+			errWorkshopSizeBudget,
+			workshop.FormatSize(report.Total),
+			workshop.FormatSize(budget),
+			workshop.FormatSize(report.Total-budget),
+		)
+	}
+
+	return nil
+}
+
+func (w *Workshop) gitCommit() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = w.mod.pathAbs
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// templateVars returns the default template variables. In a reproducible mode,
+// the build date is the archive modification time, so the same sources always
+// result in the same files.
+func (w *Workshop) templateVars(ws workshop.Controller) (map[string]string, error) {
+	buildDate := time.Now().UTC()
+	switch {
+	case ws.Reproducible():
+		buildDate = ws.ModTime()
+	case len(os.Getenv("SOURCE_DATE_EPOCH")) > 0:
+		modTime, err := workshop.ModTimeFromEnv()
 		if err != nil {
-			return nil
+			return nil, err
+		}
+		buildDate = modTime
+	}
+
+	commit, err := w.gitCommit()
+	if err != nil {
+		printWarning("failed to get git commit", err)
+		commit = "unknown"
+	}
+
+	return map[string]string{
+		"build_date": buildDate.Format("2006-01-02"),
+		"commit":     commit,
+		"name":       w.mod.name,
+		"version":    w.mod.version,
+	}, nil
+}
+
+func (w *Workshop) addTemplateTransforms(ws *workshop.Workshop) error {
+	if len(w.cfg.Workshop.Transforms) == 0 {
+		return nil
+	}
+
+	defaults, err := w.templateVars(ws)
+	if err != nil {
+		return err
+	}
+
+	for _, transform := range w.cfg.Workshop.Transforms {
+		vars := map[string]string{}
+		for k, v := range defaults {
+			vars[k] = v
+		}
+		for k, v := range transform.Vars {
+			vars[k] = v
+		}
+		ws.AddTransform(workshop.TemplateTransform(transform.Files, vars))
+	}
+
+	return nil
+}
+
+func (w *Workshop) validate() error {
+	errs := w.workshop.Validate(w.mod.modinfo)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	printTitle(fmt.Sprintf("Validation | Errors: %d", len(errs)))
+	for _, err := range errs {
+		fmt.Printf("%s %s\n", color.RedString("error"), err.Error())
+	}
+	fmt.Println()
+
+	return fmt.Errorf("%w. Fix the errors above or use --no-validate to skip", errWorkshopValidation)
+}
+
+func (w *Workshop) confirmOverride(label string) error {
+	if w.noOverwrite {
+		return errWorkshopDestExists
+	}
+
+	if w.yes {
+		return nil
+	}
+
+	stdin := w.stdin
+	if stdin == nil {
+		if !isTerminal(os.Stdin) {
+			return fmt.Errorf("%w and can't ask to override as stdin is not a terminal. Use --yes or --no-overwrite", errWorkshopDestExists)
 		}
+		stdin = os.Stdin
+	}
+
+	prompt := promptui.Prompt{
+		Label:     label,
+		Default:   "y",
+		IsConfirm: true,
+		Stdin:     stdin,
+		Stdout:    os.Stdout,
+	}
+
+	if _, err := prompt.Run(); err != nil {
+		return errWorkshopCanceled
 	}
 
-	if w.zip {
-		if err := w.workshop.ZipFiles(); err != nil {
+	return nil
+}
+
+func printProgress(done, total int) {
+	const width = 30
+
+	filled := width * done / total
+	fmt.Printf(
+		"\r[%s%s] %3d%% %d/%d",
+		strings.Repeat("=", filled),
+		strings.Repeat(" ", width-filled),
+		100*done/total,
+		done,
+		total,
+	)
+
+	if done == total {
+		fmt.Print("\r\033[K")
+	}
+}
+
+func (w *Workshop) copy() (err error) {
+	if w.workshop.DestExists() {
+		if err := w.confirmOverride("Destination already exists. Override"); err != nil {
 			return err
 		}
-	} else {
-		if err := w.workshop.CopyFiles(); err != nil {
+	}
+
+	switch w.workshop.Format() {
+	case workshop.FormatZip:
+		err = w.workshop.ZipFiles()
+	case workshop.FormatTarGz:
+		err = w.workshop.TarFiles()
+	default:
+		err = w.workshop.CopyFiles()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if w.workshop.Format().IsArchive() {
+		checksum, err := w.workshop.ArchiveChecksum()
+		if err != nil {
 			return err
 		}
+
+		printNameValue("SHA-256", checksum)
 	}
 
 	fmt.Println("Done")
 	return nil
 }
 
-func (w *Workshop) run() error {
+func (w *Workshop) installFolder() string {
+	if folder := w.cfg.Workshop.Install.Folder; len(folder) > 0 {
+		return folder
+	}
+
+	if f, err := w.mod.modinfo.FieldByName("folder_name"); err == nil && f != nil {
+		if val, ok := f.Value.(string); ok && len(val) > 0 {
+			return val
+		}
+	}
+
+	return workshop.WorkspaceFolderName(filepath.Base(w.mod.pathAbs))
+}
+
+func (w *Workshop) installItem() error {
+	cfg := w.cfg.Workshop.Install
+	if len(cfg.Target) == 0 {
+		return errors.New("target is not set. Use --target or workshop.install.target")
+	}
+
+	target, err := filepath.Abs(cfg.Target)
+	if err != nil {
+		return err
+	}
+
+	if stat, err := os.Stat(target); err != nil || !stat.IsDir() {
+		return fmt.Errorf("target %s is not a directory", target)
+	}
+
+	dest := filepath.Join(target, w.installFolder())
+	if stat, err := os.Lstat(dest); err == nil && stat.Mode()&os.ModeSymlink == 0 {
+		if err := w.confirmOverride("Mod folder already exists. Override"); err != nil {
+			return err
+		}
+	}
+
+	if err := w.workshop.Install(dest, cfg.Symlink); err != nil {
+		return err
+	}
+
+	if cfg.Symlink {
+		printNameValue("Linked", dest)
+		return nil
+	}
+
+	printNameValue("Installed", dest)
+	return nil
+}
+
+func (w *Workshop) loadChangeNote() (string, error) {
+	if len(w.changeNote) > 0 {
+		return w.changeNote, nil
+	}
+
+	p := w.cfg.Workshop.Publish.Changelog
+	if len(p) == 0 {
+		return "", nil
+	}
+
+	if !filepath.IsAbs(p) {
+		p = path.Join(w.mod.pathAbs, p)
+	}
+
+	if _, err := os.Stat(p); err != nil {
+		return "", nil
+	}
+
+	c := changelog.New()
+	if err := c.Load(p); err != nil {
+		return "", err
+	}
+
+	release := c.ReleaseByVersion(w.mod.version)
+	if release == nil {
+		release = c.LatestVersionRelease()
+	}
+
+	if release == nil {
+		return "", nil
+	}
+
+	return release.PlainText(), nil
+}
+
+func (w *Workshop) prepareItem() (*workshop.Item, error) {
+	cfg := w.cfg.Workshop.Publish
+
+	changeNote, err := w.loadChangeNote()
+	if err != nil {
+		return nil, err
+	}
+
+	item := workshop.NewItem()
+	item.ChangeNote = changeNote
+	item.ContentFolder = w.workshop.AbsDestPath()
+	item.Title = w.mod.name
+	item.Visibility = cfg.Visibility
+
+	if len(cfg.ID) > 0 {
+		item.PublishedFileID = cfg.ID
+	}
+
+	if description, err := w.mod.modinfo.FieldByName("description"); err == nil && description != nil {
+		if val, ok := description.Value.(string); ok {
+			item.Description = val
+		}
+	}
+
+	if len(cfg.Preview) > 0 {
+		preview := cfg.Preview
+		if !filepath.IsAbs(preview) {
+			preview = path.Join(w.mod.pathAbs, preview)
+		}
+
+		if _, err := os.Stat(preview); err != nil {
+			return nil, err
+		}
+
+		item.PreviewFile = preview
+	}
+
+	return item, nil
+}
+
+func (w *Workshop) publishItem() error {
+	cfg := w.cfg.Workshop.Publish
+
+	item, err := w.prepareItem()
+	if err != nil {
+		return err
+	}
+
+	vdf := w.workshop.VDFPath()
+	if err := item.WriteVDF(vdf); err != nil {
+		return err
+	}
+
+	printNameValue("VDF", vdf)
+
+	if w.dryRun {
+		fmt.Println()
+		fmt.Print(item.VDF())
+		return nil
+	}
+
+	if len(cfg.Username) == 0 {
+		return errors.New("username is not set. Use --username or workshop.publish.username")
+	}
+
+	steamcmd, err := tools.NewSteamCMD()
+	if err != nil {
+		return err
+	}
+
+	steamcmd.Cmd = cfg.SteamCMD
+	if !steamcmd.ExistsOnSystem() {
+		return fmt.Errorf("%s is not available on the system", cfg.SteamCMD)
+	}
+
+	fmt.Println("---")
+	if err := steamcmd.BuildWorkshopItem(cfg.Username, vdf); err != nil {
+		return err
+	}
+
+	id, err := workshop.ReadPublishedFileID(vdf)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("---")
+	printNameValue("Published File ID", id)
+
+	if id != item.PublishedFileID {
+		fmt.Println("Set workshop.publish.id to update this item next time")
+	}
+
+	return nil
+}
+
+func packageTree(files []workshop.PackageFile) (entries []workshop.SizeEntry, names []string) {
+	entries = append(entries, workshop.SizeEntry{Name: "."})
+	isDir := []bool{true}
+	dirs := map[string]int{".": 0}
+
+	for _, f := range files {
+		parts := strings.Split(f.Name, "/")
+		entries[0].Files++
+		entries[0].Size += f.Size
+
+		for i := range parts[:len(parts)-1] {
+			name := strings.Join(parts[:i+1], "/")
+			idx, ok := dirs[name]
+			if !ok {
+				idx = len(entries)
+				dirs[name] = idx
+				entries = append(entries, workshop.SizeEntry{Name: name, Depth: i + 1})
+				isDir = append(isDir, true)
+			}
+			entries[idx].Files++
+			entries[idx].Size += f.Size
+		}
+
+		entries = append(entries, workshop.SizeEntry{
+			Name:  f.Name,
+			Depth: len(parts),
+			Files: 1,
+			Size:  f.Size,
+		})
+		isDir = append(isDir, false)
+	}
+
+	names = sizeTreeNames(entries)
+	for i := range names {
+		if isDir[i] && i > 0 {
+			names[i] += "/"
+		}
+	}
+
+	return entries, names
+}
+
+func printDiffFiles(prefix string, c func(string, ...interface{}) string, files []string) {
+	for _, file := range files {
+		fmt.Println(c("%s %s", prefix, file))
+	}
+}
+
+// diffPackage prepares files from the source the same way as for a build and
+// compares them with the package.
+func (w *Workshop) diffPackage(pkg *workshop.Package) (*workshop.PackageDiff, error) {
+	if err := w.prepare(); err != nil {
+		return nil, err
+	}
+
+	if err := w.compileAssets(); err != nil {
+		return nil, err
+	}
+
+	return w.workshop.Diff(pkg)
+}
+
+func printPackage(pkg *workshop.Package) error {
+	absPath, err := filepath.Abs(pkg.Path())
+	if err != nil {
+		return err
+	}
+
+	printTitle("Package")
+	printNameValue("Path", absPath)
+	printNameValue("Format", pkg.Format().String())
+	printNameValue("Files", len(pkg.Files()))
+	printNameValue("Size", fmt.Sprintf("%s (%d bytes)", workshop.FormatSize(pkg.Size()), pkg.Size()))
+	fmt.Println()
+
+	if info, err := pkg.ModInfo(); err != nil {
+		printWarning("failed to load modinfo.lua", err)
+		fmt.Println()
+	} else {
+		i := NewInfo()
+		i.modinfo = info
+		printTitle("Info")
+		i.printGeneral()
+		i.printCompatibility()
+		i.printOther()
+		fmt.Println()
+	}
+
+	entries, names := packageTree(pkg.Files())
+	printTitle(fmt.Sprintf("Files | Total: %d", len(pkg.Files())))
+	printSizeEntries(entries, names, false)
+
+	return nil
+}
+
+func printPackageDiff(packageDiff *workshop.PackageDiff) {
+	printTitle(fmt.Sprintf(
+		"Diff | Added: %d | Removed: %d | Modified: %d",
+		len(packageDiff.Added),
+		len(packageDiff.Removed),
+		len(packageDiff.Modified),
+	))
+
+	if packageDiff.IsEmpty() {
+		fmt.Println("Package matches the source")
+		return
+	}
+
+	printDiffFiles("+", color.GreenString, packageDiff.Added)
+	printDiffFiles("-", color.RedString, packageDiff.Removed)
+	printDiffFiles("~", color.YellowString, packageDiff.Modified)
+}
+
+func (w *Workshop) inspect(pkgPath string, diff bool) error {
+	pkg, err := workshop.OpenPackage(pkgPath)
+	if err != nil {
+		return err
+	}
+
+	var packageDiff *workshop.PackageDiff
+	if diff {
+		if packageDiff, err = w.diffPackage(pkg); err != nil {
+			return err
+		}
+	}
+
+	if err := printPackage(pkg); err != nil {
+		return err
+	}
+
+	if packageDiff != nil {
+		fmt.Println()
+		printPackageDiff(packageDiff)
+	}
+
+	return nil
+}
+
+// destFormat returns the output format. Publishing and installing always use a
+// directory.
+func (w *Workshop) destFormat() (workshop.Format, error) {
+	format, err := workshop.ParseFormat(w.cfg.Workshop.Format)
+	if err != nil {
+		return format, err
+	}
+
+	if w.publish || w.install {
+		format = workshop.FormatDir
+	}
+
+	return format, nil
+}
+
+// destPath returns the destination path either inside the mod directory or
+// inside the configured output directory.
+func (w *Workshop) destPath() (string, error) {
+	out := w.mod.pathAbs
+	if len(w.cfg.Workshop.Out) > 0 {
+		abs, err := filepath.Abs(w.cfg.Workshop.Out)
+		if err != nil {
+			return "", err
+		}
+		out = abs
+	}
+	return path.Join(out, w.destName), nil
+}
+
+// ignoreList returns the configured ignore list along with the destination and
+// assets sources when they are inside the mod directory.
+func (w *Workshop) ignoreList(ws *workshop.Workshop) []string {
+	ignore := append([]string(nil), w.cfg.Workshop.Ignore...)
+	if !strings.HasPrefix(ws.RelDestPath(), "..") {
+		ignore = append(ignore, w.destName)
+		ignore = append(ignore, filepath.Base(ws.VDFPath()))
+	}
+	if assets := w.cfg.Workshop.Assets; len(assets.Src) > 0 {
+		for _, dir := range []string{assets.Src, assets.Cache} {
+			if pattern, ok := dirPattern(w.mod.pathAbs, dir); ok {
+				ignore = append(ignore, pattern)
+			}
+		}
+	}
+	return ignore
+}
+
+// dirPattern returns an ignore pattern matching only the provided directory
+// relative to the mod root like "/assets/src/" for "./assets/src/". It returns
+// false for the mod root itself or a directory outside of it.
+func dirPattern(root, dir string) (string, bool) {
+	if filepath.IsAbs(dir) {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return "", false
+		}
+		dir = rel
+	}
+
+	dir = filepath.ToSlash(filepath.Clean(dir))
+	if dir == "." || dir == ".." || strings.HasPrefix(dir, "../") {
+		return "", false
+	}
+	return "/" + strings.Trim(dir, "/") + "/", true
+}
+
+// configure applies the archive, transform and jobs settings.
+func (w *Workshop) configure(ws *workshop.Workshop) error {
+	if err := ws.SetCompressionLevel(w.cfg.Workshop.Compression); err != nil {
+		return err
+	}
+
+	if w.cfg.Workshop.Reproducible {
+		modTime, err := workshop.ModTimeFromEnv()
+		if err != nil {
+			return err
+		}
+		ws.SetReproducible(true)
+		ws.SetModTime(modTime)
+	}
+
+	if err := w.addTemplateTransforms(ws); err != nil {
+		return err
+	}
+
+	ws.SetJobs(w.cfg.Workshop.Jobs)
+	if isTerminal(os.Stdout) {
+		ws.SetProgress(printProgress)
+	}
+
+	if minify := w.cfg.Workshop.Minify; minify.Enabled {
+		ws.AddTransform(workshop.MinifyTransform(minify.KeepLines, minify.Whitespace))
+	}
+
+	return nil
+}
+
+func (w *Workshop) prepare() error {
 	w.mod = NewMod()
 	if err := w.mod.Load(w.path); err != nil {
 		return err
 	}
 
-	ws, err := workshop.New(w.mod.pathAbs, path.Join(w.mod.pathAbs, w.destName))
+	format, err := w.destFormat()
+	if err != nil {
+		return err
+	}
+
+	dest, err := w.destPath()
+	if err != nil {
+		return err
+	}
+
+	ws, err := workshop.New(w.mod.pathAbs, dest)
 	if err != nil {
 		return err
 	}
 
-	ignore := w.cfg.Workshop.Ignore
-	ignore = append(ignore, w.destName)
-	ws.SetIgnore(ignore)
+	ws.SetFormat(format)
+	ws.SetIgnore(w.ignoreList(ws))
+	ws.SetInclude(w.cfg.Workshop.Include)
+	ws.SetMaxSize(w.cfg.Workshop.MaxSize)
+
+	if err := w.configure(ws); err != nil {
+		return err
+	}
 
 	w.workshop = ws
 
-	if _, _, err := ws.GetFiles(); err != nil {
+	_, _, err = ws.GetFiles()
+	return err
+}
+
+func (w *Workshop) run() error {
+	if err := w.prepare(); err != nil {
+		return err
+	}
+
+	if err := w.compileAssets(); err != nil {
 		return err
 	}
 
@@ -111,14 +1008,50 @@ func (w *Workshop) run() error {
 		return nil
 	}
 
+	if w.analyze {
+		return w.printAnalysis()
+	}
+
+	if w.size {
+		return w.printSize()
+	}
+
 	if err := w.printDefault(); err != nil {
 		return err
 	}
 
 	fmt.Println("---")
+	if w.cfg.Workshop.Validate {
+		if err := w.validate(); err != nil {
+			return err
+		}
+	}
+
 	if err := w.copy(); err != nil {
 		return err
 	}
 
+	if w.install {
+		return w.installItem()
+	}
+
+	if w.publish {
+		return w.publishItem()
+	}
+
 	return nil
 }
+
+func workshopExitCode(err error) int {
+	switch {
+	case errors.Is(err, errWorkshopValidation), errors.Is(err, errWorkshopSizeBudget):
+		return exitCodeValidation
+	case errors.Is(err, errWorkshopDestExists):
+		return exitCodeDestExists
+	case errors.Is(err, errWorkshopCanceled):
+		return exitCodeCanceled
+	case errors.Is(err, errWorkshopVersion):
+		return exitCodeVersion
+	}
+	return exitCodeError
+}
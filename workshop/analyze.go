@@ -0,0 +1,138 @@
+package workshop
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dstmodders/mod-cli/modinfo"
+)
+
+// AnalyzedExt holds extensions of files that are expected to be referenced from
+// Lua files. Other included files, like LICENSE, are never reported as
+// unreferenced.
+var AnalyzedExt = []string{
+	".dyn",
+	".fev",
+	".fsb",
+	".ksh",
+	".lua",
+	".tex",
+	".xml",
+	".zip",
+}
+
+// Analysis represents a result of analyzing references between files.
+type Analysis struct {
+	// Refs holds all references found in the included Lua files.
+	Refs []Ref
+
+	// Excluded holds references to files that exist in the source directory but
+	// are not included.
+	Excluded []Ref
+
+	// Unreferenced holds included files that are never referenced.
+	Unreferenced []string
+}
+
+// Analyze parses all included Lua files retrieved earlier using GetFiles and
+// resolves their references against the included files. The provided mod info
+// is used to mark the icon as referenced and can be nil.
+func (w *Workshop) Analyze(info *modinfo.ModInfo) (*Analysis, error) {
+	refs, err := w.parseRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Analysis{
+		Refs: refs,
+	}
+
+	referenced := alwaysReferenced(info)
+	for _, ref := range result.Refs {
+		if w.HasFile(ref.Path) {
+			referenced[ref.Path] = true
+
+			// atlases are always shipped together with their textures
+			if filepath.Ext(ref.Path) == ".xml" {
+				referenced[strings.TrimSuffix(ref.Path, ".xml")+".tex"] = true
+			}
+
+			continue
+		}
+
+		if stat, err := os.Stat(ref.Path); err == nil && stat.Mode().IsRegular() {
+			result.Excluded = append(result.Excluded, ref)
+		}
+	}
+
+	for _, file := range w.files {
+		if referenced[file] || !isAnalyzedExt(file) {
+			continue
+		}
+		result.Unreferenced = append(result.Unreferenced, file)
+	}
+
+	sort.SliceStable(result.Excluded, func(i, j int) bool {
+		return result.Excluded[i].Path < result.Excluded[j].Path
+	})
+
+	sort.Strings(result.Unreferenced)
+
+	return result, nil
+}
+
+// alwaysReferenced returns files which are loaded by the game itself: mod info,
+// entry points and the icon from the provided mod info which can be nil.
+func alwaysReferenced(info *modinfo.ModInfo) map[string]bool {
+	referenced := map[string]bool{
+		"modinfo.lua": true,
+	}
+
+	for _, entry := range EntryPoints {
+		referenced[entry] = true
+	}
+
+	if info == nil {
+		return referenced
+	}
+
+	for _, field := range []string{"icon", "icon_atlas"} {
+		if f, err := info.FieldByName(field); err == nil && f != nil {
+			if val, ok := f.Value.(string); ok && len(val) > 0 {
+				referenced[filepath.Clean(val)] = true
+			}
+		}
+	}
+
+	return referenced
+}
+
+// parseRefs parses references in all included Lua files.
+func (w *Workshop) parseRefs() (result []Ref, err error) {
+	for _, file := range w.files {
+		if filepath.Ext(file) != ".lua" {
+			continue
+		}
+
+		refs, err := ParseRefs(w.SourcePath(file))
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, refs...)
+	}
+
+	return result, nil
+}
+
+func isAnalyzedExt(file string) bool {
+	ext := strings.ToLower(filepath.Ext(file))
+	for _, e := range AnalyzedExt {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package workshop
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dstmodders/mod-cli/modinfo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkshop_Analyze(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"LICENSE":             "license",
+		"modinfo.lua":         `icon = "modicon.tex"`,
+		"modicon.tex":         "tex",
+		"modmain.lua":         `require("foo"); modimport("ignored")`,
+		"scripts/foo.lua":     `Asset("ATLAS", "images/icon.xml")`,
+		"scripts/unused.lua":  `return {}`,
+		"images/icon.tex":     "tex",
+		"images/icon.xml":     "xml",
+		"images/unused.tex":   "tex",
+		"ignored.lua":         `return {}`,
+		"ignored/missing.lua": `require("missing")`,
+	})
+
+	chdir(t, src)
+	w, err := New(src, filepath.Join(t.TempDir(), "workshop"))
+	assert.Nil(t, err)
+	w.SetIgnore([]string{"ignored*"})
+	_, _, err = w.GetFiles()
+	assert.Nil(t, err)
+
+	info := modinfo.New()
+	assert.Nil(t, info.LoadString(`icon = "modicon.tex"`))
+
+	analysis, err := w.Analyze(info)
+	assert.Nil(t, err)
+	assert.Len(t, analysis.Refs, 3)
+
+	if assert.Len(t, analysis.Excluded, 1) {
+		assert.Equal(t, "ignored.lua", analysis.Excluded[0].Path)
+	}
+
+	assert.Equal(t, []string{
+		filepath.FromSlash("images/unused.tex"),
+		filepath.FromSlash("scripts/unused.lua"),
+	}, analysis.Unreferenced)
+}
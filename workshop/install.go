@@ -0,0 +1,79 @@
+package workshop
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const regexFolderName string = `[^A-Za-z0-9._-]+`
+
+var folderNameRegex = regexp.MustCompile(regexFolderName)
+
+// WorkspaceFolderName returns a mod folder name like "workspace-name" used for
+// mods installed locally from the provided name.
+func WorkspaceFolderName(name string) string {
+	name = folderNameRegex.ReplaceAllString(strings.TrimSpace(name), "-")
+	return "workspace-" + strings.Trim(name, "-")
+}
+
+// isInside checks if the path is the same as or inside the parent directory.
+func isInside(parent, path string) bool {
+	rel, err := filepath.Rel(parent, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Install installs files copied earlier using CopyFiles into the provided
+// directory, which usually is a mod folder inside the game "mods" directory.
+// When symlink is true, the directory becomes a symbolic link to the
+// destination path instead, so subsequent builds are picked up without
+// reinstalling. An existing directory or link is replaced only when the new
+// one is complete.
+func (w *Workshop) Install(dest string, symlink bool) error {
+	if w.format != FormatDir {
+		return errors.New("only a directory can be installed")
+	}
+
+	dest, err := filepath.Abs(dest)
+	if err != nil {
+		return err
+	}
+
+	if isInside(dest, w.absDestPath) {
+		return errors.New("install path can't contain the destination path")
+	}
+
+	if isInside(dest, w.srcDir.AbsPath()) {
+		return errors.New("install path can't contain the source path")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return err
+	}
+
+	if symlink {
+		staging := dest + StagingSuffix
+		if err := os.RemoveAll(staging); err != nil {
+			return err
+		}
+
+		if err := os.Symlink(w.absDestPath, staging); err != nil {
+			return err
+		}
+
+		return commitStaged(staging, dest)
+	}
+
+	install := *w
+	install.absDestPath = dest
+	install.sources = map[string]string{}
+	for _, file := range w.files {
+		install.sources[file] = filepath.Join(w.absDestPath, file)
+	}
+	install.transforms = nil
+	install.progress = nil
+
+	return install.CopyFiles()
+}
@@ -0,0 +1,71 @@
+package workshop
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaceFolderName(t *testing.T) {
+	testCases := map[string]string{
+		"Test":           "workspace-Test",
+		" My Mod! ":      "workspace-My-Mod",
+		"[API] Mod v1.0": "workspace-API-Mod-v1.0",
+	}
+
+	for name, expected := range testCases {
+		assert.Equalf(t, expected, WorkspaceFolderName(name), `Name "%s"`, name)
+	}
+}
+
+func TestWorkshop_Install(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "mods", "mod")
+	writeFiles(t, src, packageFiles)
+
+	w := newWorkshop(t, src, FormatDir, 1)
+	build(t, w)
+
+	dest := filepath.Join(t.TempDir(), "mods", "workspace-Test")
+	assert.Nil(t, w.Install(dest, false))
+
+	p, err := OpenPackage(dest)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"modinfo.lua", "modmain.lua", "scripts/foo.lua"}, packageNames(p))
+
+	assert.Nil(t, w.Install(dest, true))
+	target, err := os.Readlink(dest)
+	assert.Nil(t, err)
+	assert.Equal(t, w.AbsDestPath(), target)
+}
+
+func TestWorkshop_Install_Invalid(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "mods", "mod")
+	writeFiles(t, src, packageFiles)
+
+	w := newWorkshop(t, src, FormatDir, 1)
+	build(t, w)
+
+	tests := []struct {
+		name     string
+		dest     string
+		expected string
+	}{
+		{"destination path", w.AbsDestPath(), "install path can't contain the destination path"},
+		{"destination parent", filepath.Dir(w.AbsDestPath()), "install path can't contain the destination path"},
+		{"source path", src, "install path can't contain the source path"},
+		{"source parent", filepath.Dir(src), "install path can't contain the source path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, symlink := range []bool{false, true} {
+				assert.EqualError(t, w.Install(tt.dest, symlink), tt.expected)
+			}
+		})
+	}
+
+	_, err := os.Stat(filepath.Join(src, "modinfo.lua"))
+	assert.Nil(t, err, "source should be kept")
+}
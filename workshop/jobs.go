@@ -0,0 +1,182 @@
+package workshop
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"runtime"
+)
+
+// ProgressFunc represents a function called after each file is processed by
+// CopyFiles, ZipFiles or TarFiles.
+type ProgressFunc func(done, total int)
+
+// packedFile represents a file prepared by a worker.
+type packedFile struct {
+	name  string
+	stat  os.FileInfo
+	data  []byte
+	size  int64
+	crc32 uint32
+}
+
+// SetJobs sets the number of files processed in parallel. Zero or a negative
+// value uses the number of CPUs.
+func (w *Workshop) SetJobs(jobs int) {
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	w.jobs = jobs
+}
+
+// Jobs gets the number of files processed in parallel.
+func (w *Workshop) Jobs() int {
+	if w.jobs <= 0 {
+		return 1
+	}
+	return w.jobs
+}
+
+// SetProgress sets a function called after each processed file.
+func (w *Workshop) SetProgress(fn ProgressFunc) {
+	w.progress = fn
+}
+
+// forEachFile runs work for the provided files using a pool of workers and
+// passes results to consume in the same order as files. Only a few files per
+// worker are held in memory at once. The first error stops processing.
+func (w *Workshop) forEachFile(
+	files []string,
+	work func(string) (*packedFile, error),
+	consume func(*packedFile) error,
+) error {
+	type result struct {
+		file *packedFile
+		err  error
+	}
+
+	jobs := w.Jobs()
+	queue := make(chan chan result, jobs)
+	sem := make(chan struct{}, jobs)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		defer close(queue)
+		for _, file := range files {
+			select {
+			case sem <- struct{}{}:
+			case <-done:
+				return
+			}
+
+			ch := make(chan result, 1)
+			go func(file string) {
+				defer func() { <-sem }()
+				f, err := work(file)
+				ch <- result{f, err}
+			}(file)
+
+			select {
+			case queue <- ch:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	processed := 0
+	for ch := range queue {
+		r := <-ch
+		if r.err != nil {
+			return r.err
+		}
+
+		if err := consume(r.file); err != nil {
+			return err
+		}
+
+		processed++
+		if w.progress != nil {
+			w.progress(processed, len(files))
+		}
+	}
+
+	return nil
+}
+
+// statFile gets the info of the provided file source and makes sure that it's
+// a regular file.
+func (w *Workshop) statFile(name string) (os.FileInfo, error) {
+	stat, err := os.Stat(w.SourcePath(name))
+	if err != nil {
+		return nil, err
+	}
+
+	if !stat.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", stat.Name())
+	}
+
+	return stat, nil
+}
+
+// readFile reads the provided file with all transform stages applied.
+func (w *Workshop) readFile(name string) (*packedFile, error) {
+	stat, err := w.statFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	src, size, err := w.openFile(name, stat)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	data := bytes.NewBuffer(make([]byte, 0, size))
+	if _, err := io.Copy(data, src); err != nil {
+		return nil, err
+	}
+
+	return &packedFile{
+		name:  name,
+		stat:  stat,
+		data:  data.Bytes(),
+		size:  int64(data.Len()),
+		crc32: crc32.ChecksumIEEE(data.Bytes()),
+	}, nil
+}
+
+// compressFile reads the provided file and compresses it using the configured
+// compression level unless compression is disabled.
+func (w *Workshop) compressFile(name string) (*packedFile, error) {
+	f, err := w.readFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.compressionLevel == flate.NoCompression {
+		return f, nil
+	}
+
+	var buf bytes.Buffer
+	compressor, err := flate.NewWriter(&buf, w.compressionLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := compressor.Write(f.data); err != nil {
+		return nil, err
+	}
+
+	if err := compressor.Close(); err != nil {
+		return nil, err
+	}
+
+	f.data = buf.Bytes()
+
+	return f, nil
+}
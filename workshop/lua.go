@@ -0,0 +1,361 @@
+package workshop
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const regexRequire string = `\brequire\s*\(?\s*["']([^"']+)["']`
+
+const regexModImport string = `\bmodimport\s*\(?\s*["']([^"']+)["']`
+
+const regexAsset string = `\bAsset\s*\(\s*["'][^"']+["']\s*,\s*["']([^"']+)["']`
+
+const regexPrefabFiles string = `\bPrefabFiles\s*=\s*\{([^}]*)\}`
+
+const regexPrefabInsert string = `\btable\.insert\s*\(\s*PrefabFiles\s*,\s*["']([^"']+)["']`
+
+const regexString string = `["']([^"']+)["']`
+
+var requireRegex *regexp.Regexp
+var modImportRegex *regexp.Regexp
+var assetRegex *regexp.Regexp
+var prefabFilesRegex *regexp.Regexp
+var prefabInsertRegex *regexp.Regexp
+var stringRegex *regexp.Regexp
+
+func init() {
+	requireRegex = regexp.MustCompile(regexRequire)
+	modImportRegex = regexp.MustCompile(regexModImport)
+	assetRegex = regexp.MustCompile(regexAsset)
+	prefabFilesRegex = regexp.MustCompile(regexPrefabFiles)
+	prefabInsertRegex = regexp.MustCompile(regexPrefabInsert)
+	stringRegex = regexp.MustCompile(regexString)
+}
+
+// RefKind represents a kind of reference to a file.
+type RefKind int
+
+const (
+	// RefKindRequire represents a module loaded by require.
+	RefKindRequire RefKind = iota
+
+	// RefKindModImport represents a file loaded by modimport.
+	RefKindModImport
+
+	// RefKindAsset represents a file declared by Asset.
+	RefKindAsset
+
+	// RefKindPrefab represents a prefab file declared in PrefabFiles.
+	RefKindPrefab
+)
+
+// String returns a string representation of a RefKind.
+func (k RefKind) String() string {
+	switch k {
+	case RefKindRequire:
+		return "require"
+	case RefKindModImport:
+		return "modimport"
+	case RefKindAsset:
+		return "Asset"
+	case RefKindPrefab:
+		return "PrefabFiles"
+	}
+	return "-"
+}
+
+// Ref represents a single reference to a file found in a Lua file.
+type Ref struct {
+	// Kind holds a reference kind.
+	Kind RefKind
+
+	// File holds a path of the Lua file where the reference has been found.
+	File string
+
+	// Name holds an original referenced name like "foo.bar" in require or
+	// "images/foo.xml" in Asset.
+	Name string
+
+	// Path holds a resolved path relative to the mod directory.
+	Path string
+}
+
+// IsLua checks if a reference points to a Lua file.
+func (r *Ref) IsLua() bool {
+	return r.Kind != RefKindAsset
+}
+
+// ParseRefs parses the provided Lua file and returns all references to other
+// files: require, modimport, Asset and PrefabFiles. Comments are ignored.
+func ParseRefs(file string) (result []Ref, err error) {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return result, err
+	}
+
+	code := string(StripLuaComments(src, true))
+
+	add := func(kind RefKind, name, p string) {
+		result = append(result, Ref{
+			Kind: kind,
+			File: file,
+			Name: name,
+			Path: p,
+		})
+	}
+
+	for _, match := range requireRegex.FindAllStringSubmatch(code, -1) {
+		add(RefKindRequire, match[1], requirePath(match[1]))
+	}
+
+	for _, match := range modImportRegex.FindAllStringSubmatch(code, -1) {
+		add(RefKindModImport, match[1], modImportPath(match[1]))
+	}
+
+	for _, match := range assetRegex.FindAllStringSubmatch(code, -1) {
+		add(RefKindAsset, match[1], filepath.FromSlash(path.Clean(match[1])))
+	}
+
+	for _, match := range prefabFilesRegex.FindAllStringSubmatch(code, -1) {
+		for _, name := range stringRegex.FindAllStringSubmatch(match[1], -1) {
+			add(RefKindPrefab, name[1], prefabPath(name[1]))
+		}
+	}
+
+	for _, match := range prefabInsertRegex.FindAllStringSubmatch(code, -1) {
+		add(RefKindPrefab, match[1], prefabPath(match[1]))
+	}
+
+	return result, nil
+}
+
+// requirePath resolves a module name passed to require into a path relative to
+// the mod directory.
+func requirePath(name string) string {
+	name = strings.ReplaceAll(name, ".", "/")
+	return filepath.FromSlash(path.Join("scripts", name+".lua"))
+}
+
+// modImportPath resolves a path passed to modimport into a path relative to the
+// mod directory.
+func modImportPath(name string) string {
+	if !strings.HasSuffix(name, ".lua") {
+		name += ".lua"
+	}
+	return filepath.FromSlash(path.Clean(name))
+}
+
+// prefabPath resolves a name from PrefabFiles into a path relative to the mod
+// directory.
+func prefabPath(name string) string {
+	return filepath.FromSlash(path.Join("scripts", "prefabs", name+".lua"))
+}
+
+// luaLongBracket returns the level of a long bracket like "[[" or "[==[" at the
+// start of the provided source or -1 if there is none.
+func luaLongBracket(src []byte) int {
+	if len(src) < 2 || src[0] != '[' {
+		return -1
+	}
+
+	level := 0
+	for level+1 < len(src) && src[level+1] == '=' {
+		level++
+	}
+
+	if level+1 < len(src) && src[level+1] == '[' {
+		return level
+	}
+
+	return -1
+}
+
+// luaSegment represents a kind of a Lua source segment.
+type luaSegment int
+
+const (
+	luaSegmentCode luaSegment = iota
+	luaSegmentString
+	luaSegmentComment
+)
+
+// luaLongBracketEnd returns the index right after the long bracket of the
+// provided level closing at or after i or the source length if it's never
+// closed.
+func luaLongBracketEnd(src []byte, i, level int) int {
+	closing := []byte("]" + strings.Repeat("=", level) + "]")
+	end := bytes.Index(src[i:], closing)
+	if end < 0 {
+		return len(src)
+	}
+	return i + end + len(closing)
+}
+
+// luaQuotedStringEnd returns the index right after the quoted string starting
+// at i. An unfinished string ends at the line break.
+func luaQuotedStringEnd(src []byte, i int) int {
+	quote := src[i]
+
+	j := i + 1
+	for j < len(src) && src[j] != quote && src[j] != '\n' {
+		if src[j] == '\\' {
+			j++
+		}
+		j++
+	}
+
+	if j < len(src) {
+		j++
+	}
+
+	if j > len(src) {
+		return len(src)
+	}
+
+	return j
+}
+
+// luaCommentEnd returns the index right after the comment starting at i. For
+// single-line comments, the line break is not included.
+func luaCommentEnd(src []byte, i int) int {
+	j := i + 2
+	if level := luaLongBracket(src[j:]); level >= 0 {
+		return luaLongBracketEnd(src, j+level+2, level)
+	}
+
+	if end := bytes.IndexByte(src[j:], '\n'); end >= 0 {
+		return j + end
+	}
+
+	return len(src)
+}
+
+// scanLua splits the provided Lua source into code, string and comment
+// segments and passes each of them to fn in order.
+func scanLua(src []byte, fn func(kind luaSegment, seg []byte)) {
+	start := 0
+	emit := func(kind luaSegment, i, j int) {
+		if i > start {
+			fn(luaSegmentCode, src[start:i])
+		}
+		if j > i {
+			fn(kind, src[i:j])
+		}
+		start = j
+	}
+
+	i := 0
+	for i < len(src) {
+		c := src[i]
+
+		switch {
+		case c == '"' || c == '\'':
+			j := luaQuotedStringEnd(src, i)
+			emit(luaSegmentString, i, j)
+			i = j
+		case c == '[' && luaLongBracket(src[i:]) >= 0:
+			level := luaLongBracket(src[i:])
+			j := luaLongBracketEnd(src, i+level+2, level)
+			emit(luaSegmentString, i, j)
+			i = j
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			j := luaCommentEnd(src, i)
+			emit(luaSegmentComment, i, j)
+			i = j
+		default:
+			i++
+		}
+	}
+
+	emit(luaSegmentCode, len(src), len(src))
+}
+
+// StripLuaComments removes all comments, including LDoc ones, from the provided
+// Lua source. When keepLines is true, line breaks inside the removed comments
+// are preserved, so line numbers in the result match the original ones. A block
+// comment without line breaks is replaced by a single space to keep the
+// surrounding tokens apart.
+func StripLuaComments(src []byte, keepLines bool) []byte {
+	var out bytes.Buffer
+
+	scanLua(src, func(kind luaSegment, seg []byte) {
+		if kind != luaSegmentComment {
+			out.Write(seg)
+			return
+		}
+
+		lines := bytes.Count(seg, []byte("\n"))
+		switch {
+		case luaLongBracket(seg[2:]) < 0:
+		case lines == 0:
+			out.WriteByte(' ')
+		case keepLines:
+			out.Write(bytes.Repeat([]byte("\n"), lines))
+		default:
+			out.WriteByte('\n')
+		}
+	})
+
+	return out.Bytes()
+}
+
+// CollapseLuaWhitespace removes indentation and trailing whitespace and
+// collapses other runs of spaces and tabs outside of strings into a single
+// space. When keepLines is false, empty lines are removed as well. Comments are
+// expected to be stripped beforehand using StripLuaComments.
+func CollapseLuaWhitespace(src []byte, keepLines bool) []byte {
+	var out bytes.Buffer
+
+	lineStart := true
+	space := false
+
+	scanLua(src, func(kind luaSegment, seg []byte) {
+		if kind != luaSegmentCode {
+			if space {
+				out.WriteByte(' ')
+				space = false
+			}
+			out.Write(seg)
+			lineStart = bytes.HasSuffix(seg, []byte("\n"))
+			return
+		}
+
+		for _, c := range seg {
+			switch c {
+			case ' ', '\t', '\r', '\f', '\v':
+				space = !lineStart
+			case '\n':
+				space = false
+				if !keepLines && (lineStart || out.Len() == 0) {
+					continue
+				}
+				out.WriteByte(c)
+				lineStart = true
+			default:
+				if space {
+					out.WriteByte(' ')
+					space = false
+				}
+				out.WriteByte(c)
+				lineStart = false
+			}
+		}
+	})
+
+	return out.Bytes()
+}
+
+// MinifyLua strips comments from the provided Lua source and, when whitespace
+// is true, collapses whitespace using CollapseLuaWhitespace.
+func MinifyLua(src []byte, keepLines, whitespace bool) []byte {
+	src = StripLuaComments(src, keepLines)
+	if whitespace {
+		src = CollapseLuaWhitespace(src, keepLines)
+	}
+	return src
+}
@@ -0,0 +1,253 @@
+package workshop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRefs(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "modmain.lua")
+	assert.Nil(t, os.WriteFile(file, []byte(`local foo = require("foo.bar")
+local baz = require 'baz'
+modimport("scripts/init")
+modimport "main.lua"
+
+-- require("commented")
+--[[
+require("commented.block")
+]]
+
+Assets = {
+	Asset("ATLAS", "images/icon.xml"),
+	Asset( 'IMAGE' , './images/icon.tex' ),
+}
+
+PrefabFiles = {
+	"foo",
+	'bar', -- "commented"
+}
+
+table.insert(PrefabFiles, "baz")
+`), 0o600))
+
+	refs, err := ParseRefs(file)
+	assert.Nil(t, err)
+
+	expected := []Ref{
+		{RefKindRequire, file, "foo.bar", filepath.FromSlash("scripts/foo/bar.lua")},
+		{RefKindRequire, file, "baz", filepath.FromSlash("scripts/baz.lua")},
+		{RefKindModImport, file, "scripts/init", filepath.FromSlash("scripts/init.lua")},
+		{RefKindModImport, file, "main.lua", "main.lua"},
+		{RefKindAsset, file, "images/icon.xml", filepath.FromSlash("images/icon.xml")},
+		{RefKindAsset, file, "./images/icon.tex", filepath.FromSlash("images/icon.tex")},
+		{RefKindPrefab, file, "foo", filepath.FromSlash("scripts/prefabs/foo.lua")},
+		{RefKindPrefab, file, "bar", filepath.FromSlash("scripts/prefabs/bar.lua")},
+		{RefKindPrefab, file, "baz", filepath.FromSlash("scripts/prefabs/baz.lua")},
+	}
+
+	assert.Equal(t, expected, refs)
+}
+
+func TestParseRefs_NotExist(t *testing.T) {
+	_, err := ParseRefs(filepath.Join(t.TempDir(), "modmain.lua"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRequirePath(t *testing.T) {
+	testCases := map[string]string{
+		"foo":         "scripts/foo.lua",
+		"foo.bar":     "scripts/foo/bar.lua",
+		"foo.bar.baz": "scripts/foo/bar/baz.lua",
+		"foo/bar":     "scripts/foo/bar.lua",
+	}
+
+	for name, expected := range testCases {
+		assert.Equalf(t, filepath.FromSlash(expected), requirePath(name), `Module "%s"`, name)
+	}
+}
+
+func TestModImportPath(t *testing.T) {
+	testCases := map[string]string{
+		"main":               "main.lua",
+		"main.lua":           "main.lua",
+		"scripts/init":       "scripts/init.lua",
+		"./scripts/init.lua": "scripts/init.lua",
+	}
+
+	for name, expected := range testCases {
+		assert.Equalf(t, filepath.FromSlash(expected), modImportPath(name), `Path "%s"`, name)
+	}
+}
+
+func TestPrefabPath(t *testing.T) {
+	testCases := map[string]string{
+		"foo":     "scripts/prefabs/foo.lua",
+		"foo_bar": "scripts/prefabs/foo_bar.lua",
+		"foo/bar": "scripts/prefabs/foo/bar.lua",
+	}
+
+	for name, expected := range testCases {
+		assert.Equalf(t, filepath.FromSlash(expected), prefabPath(name), `Prefab "%s"`, name)
+	}
+}
+
+func TestStripLuaComments(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		keepLines string
+		expected  string
+	}{
+		{
+			"line comment",
+			"a = 1 -- comment\nb = 2",
+			"a = 1 \nb = 2",
+			"a = 1 \nb = 2",
+		},
+		{
+			"LDoc comment",
+			"--- Does nothing.\n-- @tparam number a\nlocal function f(a) end",
+			"\n\nlocal function f(a) end",
+			"\n\nlocal function f(a) end",
+		},
+		{
+			"block comment",
+			"a = 1 --[[ comment ]] b = 2",
+			"a = 1   b = 2",
+			"a = 1   b = 2",
+		},
+		{
+			"multiline block comment",
+			"a = 1 --[[\ncomment\n]] b = 2\nc = 3",
+			"a = 1 \n\n b = 2\nc = 3",
+			"a = 1 \n b = 2\nc = 3",
+		},
+		{
+			"nested block comment",
+			"--[==[ outer --[[ inner ]] outer ]==]a = 1",
+			" a = 1",
+			" a = 1",
+		},
+		{
+			"unfinished block comment",
+			"a = 1 --[[ comment\nb = 2",
+			"a = 1 \n",
+			"a = 1 \n",
+		},
+		{
+			"dashes in strings",
+			`a = "-- not a comment" .. '--[[ not a comment ]]' -- comment`,
+			`a = "-- not a comment" .. '--[[ not a comment ]]' `,
+			`a = "-- not a comment" .. '--[[ not a comment ]]' `,
+		},
+		{
+			"dashes in long strings",
+			"a = [[\n-- not a comment\n]] .. [==[ ]] -- not a comment ]==] -- comment",
+			"a = [[\n-- not a comment\n]] .. [==[ ]] -- not a comment ]==] ",
+			"a = [[\n-- not a comment\n]] .. [==[ ]] -- not a comment ]==] ",
+		},
+		{
+			"escapes in strings",
+			`a = "\" -- not a comment" .. '\\' -- comment`,
+			`a = "\" -- not a comment" .. '\\' `,
+			`a = "\" -- not a comment" .. '\\' `,
+		},
+		{
+			"arithmetic",
+			"a = 1 - -1",
+			"a = 1 - -1",
+			"a = 1 - -1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.keepLines, string(StripLuaComments([]byte(tt.src), true)), "keeping lines")
+			assert.Equal(t, tt.expected, string(StripLuaComments([]byte(tt.src), false)))
+		})
+	}
+}
+
+func TestCollapseLuaWhitespace(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		keepLines string
+		expected  string
+	}{
+		{
+			"indentation and trailing whitespace",
+			"if a then\n\t  b  =  1 \t\nend\n",
+			"if a then\nb = 1\nend\n",
+			"if a then\nb = 1\nend\n",
+		},
+		{
+			"empty lines",
+			"\n\na = 1\n\n\nb = 2\n",
+			"\n\na = 1\n\n\nb = 2\n",
+			"a = 1\nb = 2\n",
+		},
+		{
+			"strings",
+			`a  =  "b   c"  ..  'd` + "\t" + `e'`,
+			`a = "b   c" .. 'd` + "\t" + `e'`,
+			`a = "b   c" .. 'd` + "\t" + `e'`,
+		},
+		{
+			"long strings",
+			"a = [[\n  b  \n\n]]\n  c = 1",
+			"a = [[\n  b  \n\n]]\nc = 1",
+			"a = [[\n  b  \n\n]]\nc = 1",
+		},
+		{
+			"escapes in strings",
+			`a = "\"  b"  ..  c`,
+			`a = "\"  b" .. c`,
+			`a = "\"  b" .. c`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.keepLines, string(CollapseLuaWhitespace([]byte(tt.src), true)), "keeping lines")
+			assert.Equal(t, tt.expected, string(CollapseLuaWhitespace([]byte(tt.src), false)))
+		})
+	}
+}
+
+func TestMinifyLua(t *testing.T) {
+	src := []byte(`-- header
+local  a = "--"  -- comment
+
+--[[
+block
+]]
+return  a
+`)
+
+	tests := []struct {
+		keepLines  bool
+		whitespace bool
+		expected   string
+	}{
+		{true, false, "\nlocal  a = \"--\"  \n\n\n\n\nreturn  a\n"},
+		{true, true, "\nlocal a = \"--\"\n\n\n\n\nreturn a\n"},
+		{false, false, "\nlocal  a = \"--\"  \n\n\n\nreturn  a\n"},
+		{false, true, "local a = \"--\"\nreturn a\n"},
+	}
+
+	for _, tt := range tests {
+		result := MinifyLua(src, tt.keepLines, tt.whitespace)
+		assert.Equalf(t, tt.expected, string(result), "keepLines: %v, whitespace: %v", tt.keepLines, tt.whitespace)
+
+		if tt.keepLines {
+			lines := strings.Split(string(result), "\n")
+			assert.Len(t, lines, strings.Count(string(src), "\n")+1)
+			assert.Equal(t, "return", strings.Fields(lines[6])[0], "return should stay on line 7")
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package workshop
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dstmodders/mod-cli/dir"
+	"github.com/dstmodders/mod-cli/modinfo"
+)
+
+// PackageFile represents a single file inside a Package.
+type PackageFile struct {
+	// Name holds a path relative to the package root.
+	Name string
+
+	// Size holds the uncompressed size in bytes.
+	Size int64
+
+	// SHA256 holds the SHA-256 checksum of the content.
+	SHA256 string
+}
+
+// Package represents a built or downloaded mod package: a directory, a ZIP
+// archive or a gzipped tarball. When all files are inside a single top-level
+// directory with modinfo.lua, like in archives created by hand, that directory
+// is treated as the package root.
+type Package struct {
+	path    string
+	format  Format
+	files   []PackageFile
+	modinfo []byte
+}
+
+// PackageDiff represents differences between files retrieved using GetFiles
+// and a Package.
+type PackageDiff struct {
+	// Added holds files that are not in the package.
+	Added []string
+
+	// Removed holds files that are only in the package.
+	Removed []string
+
+	// Modified holds files with a different content.
+	Modified []string
+}
+
+// IsEmpty checks if there are no differences.
+func (d *PackageDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// OpenPackage reads the package at the provided path and indexes all its files.
+func OpenPackage(path string) (*Package, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Package{path: path}
+
+	switch {
+	case stat.IsDir():
+		p.format = FormatDir
+		err = p.readDir()
+	case strings.HasSuffix(path, FormatTarGz.Ext()) || strings.HasSuffix(path, ".tgz"):
+		p.format = FormatTarGz
+		err = p.readTarGz()
+	default:
+		p.format = FormatZip
+		err = p.readZip()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.stripRoot()
+
+	sort.Slice(p.files, func(i, j int) bool {
+		return p.files[i].Name < p.files[j].Name
+	})
+
+	return p, nil
+}
+
+func (p *Package) add(name string, src io.Reader) error {
+	hash := sha256.New()
+
+	var content strings.Builder
+	dest := io.Writer(hash)
+	if path.Base(name) == "modinfo.lua" {
+		dest = io.MultiWriter(hash, &content)
+	}
+
+	size, err := io.Copy(dest, src)
+	if err != nil {
+		return err
+	}
+
+	if name == "modinfo.lua" || (len(p.modinfo) == 0 && path.Base(name) == "modinfo.lua") {
+		p.modinfo = []byte(content.String())
+	}
+
+	p.files = append(p.files, PackageFile{
+		Name:   name,
+		Size:   size,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	})
+
+	return nil
+}
+
+func (p *Package) readDir() error {
+	d, err := dir.New(p.path)
+	if err != nil {
+		return err
+	}
+
+	return filepath.Walk(d.AbsPath(), func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(d.AbsPath(), file)
+		if err != nil {
+			return err
+		}
+
+		src, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		return p.add(filepath.ToSlash(rel), src)
+	})
+}
+
+func (p *Package) readZip() error {
+	r, err := zip.OpenReader(p.path)
+	if err != nil {
+		return fmt.Errorf("unsupported package %s: %w", p.path, err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		src, err := f.Open()
+		if err != nil {
+			return err
+		}
+
+		err = p.add(path.Clean(f.Name), src)
+		_ = src.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Package) readTarGz() error {
+	f, err := os.Open(p.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := p.add(path.Clean(header.Name), tarReader); err != nil {
+			return err
+		}
+	}
+}
+
+// stripRoot strips a single top-level directory shared by all files when
+// modinfo.lua is inside it.
+func (p *Package) stripRoot() {
+	if len(p.files) == 0 {
+		return
+	}
+
+	root := strings.SplitN(p.files[0].Name, "/", 2)[0] + "/"
+	hasModInfo := false
+	for _, f := range p.files {
+		if !strings.HasPrefix(f.Name, root) {
+			return
+		}
+		if f.Name == root+"modinfo.lua" {
+			hasModInfo = true
+		}
+	}
+
+	if !hasModInfo {
+		return
+	}
+
+	for i := range p.files {
+		p.files[i].Name = strings.TrimPrefix(p.files[i].Name, root)
+	}
+}
+
+// Path gets the package path.
+func (p *Package) Path() string {
+	return p.path
+}
+
+// Format gets the package format.
+func (p *Package) Format() Format {
+	return p.format
+}
+
+// Files gets all package files sorted by name.
+func (p *Package) Files() []PackageFile {
+	return p.files
+}
+
+// Size gets the total size of all package files in bytes.
+func (p *Package) Size() (size int64) {
+	for _, f := range p.files {
+		size += f.Size
+	}
+	return size
+}
+
+// ModInfo loads modinfo.lua from the package.
+func (p *Package) ModInfo() (*modinfo.ModInfo, error) {
+	if len(p.modinfo) == 0 {
+		return nil, errors.New("modinfo.lua not found")
+	}
+
+	info := modinfo.New()
+	if err := info.LoadString(string(p.modinfo)); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// Diff compares files retrieved earlier using GetFiles, with all transform
+// stages applied, against the provided package.
+func (w *Workshop) Diff(p *Package) (*PackageDiff, error) {
+	result := &PackageDiff{}
+
+	packaged := map[string]PackageFile{}
+	for _, f := range p.files {
+		packaged[f.Name] = f
+	}
+
+	seen := map[string]bool{}
+	for _, file := range w.files {
+		name := filepath.ToSlash(file)
+		seen[name] = true
+
+		pf, ok := packaged[name]
+		if !ok {
+			result.Added = append(result.Added, name)
+			continue
+		}
+
+		f, err := w.readFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(f.data)
+		if hex.EncodeToString(sum[:]) != pf.SHA256 {
+			result.Modified = append(result.Modified, name)
+		}
+	}
+
+	for _, f := range p.files {
+		if !seen[f.Name] {
+			result.Removed = append(result.Removed, f.Name)
+		}
+	}
+
+	sort.Strings(result.Added)
+	sort.Strings(result.Modified)
+
+	return result, nil
+}
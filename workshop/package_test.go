@@ -0,0 +1,129 @@
+package workshop
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var packageFiles = map[string]string{
+	"modinfo.lua":     `name = "Test"`,
+	"modmain.lua":     `print("modmain")`,
+	"scripts/foo.lua": `return "foo"`,
+}
+
+func packageNames(p *Package) (names []string) {
+	for _, f := range p.Files() {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestOpenPackage(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, packageFiles)
+
+	for _, format := range []Format{FormatDir, FormatZip, FormatTarGz} {
+		p, err := OpenPackage(build(t, newWorkshop(t, src, format, 1)))
+		assert.Nil(t, err)
+		assert.Equal(t, format, p.Format())
+		assert.Equal(t, []string{"modinfo.lua", "modmain.lua", "scripts/foo.lua"}, packageNames(p))
+		assert.Equal(t, int64(41), p.Size())
+
+		info, err := p.ModInfo()
+		assert.Nil(t, err)
+		name, err := info.FieldByName("name")
+		assert.Nil(t, err)
+		assert.Equal(t, "Test", name.Value)
+	}
+}
+
+func TestOpenPackage_Root(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mod.zip")
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"mod/modinfo.lua", "mod/modmain.lua"} {
+		w, err := zw.Create(name)
+		assert.Nil(t, err)
+		_, err = w.Write([]byte(packageFiles[filepath.Base(name)]))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, zw.Close())
+	assert.Nil(t, f.Close())
+
+	p, err := OpenPackage(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"modinfo.lua", "modmain.lua"}, packageNames(p))
+}
+
+func TestPackage_stripRoot(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		expected []string
+	}{
+		{"empty", nil, nil},
+		{
+			"shared root with modinfo.lua",
+			[]string{"mod/modinfo.lua", "mod/scripts/foo.lua"},
+			[]string{"modinfo.lua", "scripts/foo.lua"},
+		},
+		{
+			"shared root without modinfo.lua",
+			[]string{"mod/modmain.lua", "mod/scripts/modinfo.lua"},
+			[]string{"mod/modmain.lua", "mod/scripts/modinfo.lua"},
+		},
+		{
+			"different roots",
+			[]string{"mod/modinfo.lua", "other/modmain.lua"},
+			[]string{"mod/modinfo.lua", "other/modmain.lua"},
+		},
+		{
+			"no root",
+			[]string{"modinfo.lua", "scripts/foo.lua"},
+			[]string{"modinfo.lua", "scripts/foo.lua"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Package{}
+			for _, name := range tt.files {
+				p.files = append(p.files, PackageFile{Name: name})
+			}
+
+			p.stripRoot()
+			assert.Equal(t, tt.expected, packageNames(p))
+		})
+	}
+}
+
+func TestWorkshop_Diff(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, packageFiles)
+
+	p, err := OpenPackage(build(t, newWorkshop(t, src, FormatZip, 1)))
+	assert.Nil(t, err)
+
+	diff, err := newWorkshop(t, src, FormatZip, 1).Diff(p)
+	assert.Nil(t, err)
+	assert.True(t, diff.IsEmpty())
+
+	writeFiles(t, src, map[string]string{
+		"modmain.lua":     `print("changed")`,
+		"scripts/bar.lua": `return "bar"`,
+	})
+	assert.Nil(t, os.Remove(filepath.Join(src, "scripts", "foo.lua")))
+
+	diff, err = newWorkshop(t, src, FormatZip, 1).Diff(p)
+	assert.Nil(t, err)
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, []string{"scripts/bar.lua"}, diff.Added)
+	assert.Equal(t, []string{"scripts/foo.lua"}, diff.Removed)
+	assert.Equal(t, []string{"modmain.lua"}, diff.Modified)
+}
@@ -0,0 +1,159 @@
+package workshop
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SizeEntry represents a size of a single file, directory or extension.
+type SizeEntry struct {
+	// Name holds a file or directory path relative to the package root or an
+	// extension like ".lua".
+	Name string
+
+	// Depth holds a directory depth starting from 0 for the package root. Only
+	// used for directories.
+	Depth int
+
+	// Files holds the number of files.
+	Files int
+
+	// Size holds the total size in bytes.
+	Size int64
+}
+
+// SizeReport represents a breakdown of the package size.
+type SizeReport struct {
+	// Total holds the total size of packaged files in bytes after all transform
+	// stages.
+	Total int64
+
+	// Compressed holds the estimated total size in bytes after compressing each
+	// file using the configured compression level.
+	Compressed int64
+
+	// Dirs holds directories in a depth-first order, each including the size of
+	// its subdirectories.
+	Dirs []SizeEntry
+
+	// Exts holds extensions sorted by size in a descending order.
+	Exts []SizeEntry
+
+	// Largest holds the largest files sorted by size in a descending order.
+	Largest []SizeEntry
+}
+
+// sizeEntries holds size entries by their names.
+type sizeEntries map[string]*SizeEntry
+
+func (e sizeEntries) add(name string, depth int, size int64) {
+	entry, ok := e[name]
+	if !ok {
+		entry = &SizeEntry{Name: name, Depth: depth}
+		e[name] = entry
+	}
+	entry.Files++
+	entry.Size += size
+}
+
+// addDirs adds the file size to the package root and each of the file parent
+// directories.
+func (e sizeEntries) addDirs(file string, size int64) {
+	e.add(".", 0, size)
+	if dir := filepath.Dir(file); dir != "." {
+		parts := strings.Split(filepath.ToSlash(dir), "/")
+		for i := range parts {
+			e.add(filepath.FromSlash(strings.Join(parts[:i+1], "/")), i+1, size)
+		}
+	}
+}
+
+func (e sizeEntries) list() (result []SizeEntry) {
+	for _, entry := range e {
+		result = append(result, *entry)
+	}
+	return result
+}
+
+// Size calculates a size breakdown of files retrieved earlier using GetFiles.
+// The largest argument limits the number of the largest files in the report.
+func (w *Workshop) Size(largest int) (*SizeReport, error) {
+	report := &SizeReport{}
+	dirs := sizeEntries{}
+	exts := sizeEntries{}
+
+	for _, file := range w.files {
+		f, err := w.compressFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		size := f.size
+		report.Total += size
+		report.Compressed += int64(len(f.data))
+		report.Largest = append(report.Largest, SizeEntry{Name: file, Files: 1, Size: size})
+
+		ext := strings.ToLower(filepath.Ext(file))
+		if len(ext) == 0 {
+			ext = "-"
+		}
+		exts.add(ext, 0, size)
+		dirs.addDirs(file, size)
+	}
+
+	report.Dirs = dirs.list()
+	sortByPath(report.Dirs)
+
+	report.Exts = exts.list()
+	sortBySize(report.Exts)
+	sortBySize(report.Largest)
+
+	if largest >= 0 && len(report.Largest) > largest {
+		report.Largest = report.Largest[:largest]
+	}
+
+	return report, nil
+}
+
+// sortByPath sorts directories in a depth-first order starting from the
+// package root.
+func sortByPath(entries []SizeEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i].Name, entries[j].Name
+		if a == "." || b == "." {
+			return a == "."
+		}
+		return filepath.ToSlash(a) < filepath.ToSlash(b)
+	})
+}
+
+func sortBySize(entries []SizeEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Size == entries[j].Size {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].Size > entries[j].Size
+	})
+}
+
+const sizeUnits = "KMGTPE"
+
+// FormatSize returns a human-readable representation of the provided size in
+// bytes like "1.5 MiB".
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	// switch to the next unit when rounding would show 1024.0
+	value, exp := float64(size)/unit, 0
+	for value >= unit-0.05 && exp < len(sizeUnits)-1 {
+		value /= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", value, sizeUnits[exp])
+}
@@ -0,0 +1,82 @@
+package workshop
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatSize(t *testing.T) {
+	testCases := map[int64]string{
+		0:                     "0 B",
+		1:                     "1 B",
+		1023:                  "1023 B",
+		1024:                  "1.0 KiB",
+		1536:                  "1.5 KiB",
+		1<<20 - 1:             "1.0 MiB",
+		1 << 20:               "1.0 MiB",
+		1<<20 + 1<<19:         "1.5 MiB",
+		1<<30 - 1:             "1.0 GiB",
+		1 << 30:               "1.0 GiB",
+		1<<30 - 1<<20*100:     "924.0 MiB",
+		1 << 40:               "1.0 TiB",
+		1 << 50:               "1.0 PiB",
+		1 << 60:               "1.0 EiB",
+		math.MaxInt64:         "8.0 EiB",
+		1024*1023 + 1024*9/10: "1023.9 KiB",
+	}
+
+	for size, expected := range testCases {
+		assert.Equalf(t, expected, FormatSize(size), "Size %d", size)
+	}
+}
+
+func TestWorkshop_Size(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"modinfo.lua":             strings.Repeat("a", 10),
+		"modmain.lua":             strings.Repeat("a", 30),
+		"LICENSE":                 strings.Repeat("a", 5),
+		"images/icon.tex":         strings.Repeat("a", 100),
+		"images/icon.xml":         strings.Repeat("a", 20),
+		"scripts/foo.lua":         strings.Repeat("a", 30),
+		"scripts/prefabs/bar.lua": strings.Repeat("a", 40),
+	})
+
+	w := newWorkshop(t, src, FormatZip, 1)
+
+	report, err := w.Size(3)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(235), report.Total)
+	assert.Greater(t, report.Compressed, int64(0))
+
+	assert.Equal(t, []SizeEntry{
+		{Name: ".", Depth: 0, Files: 7, Size: 235},
+		{Name: "images", Depth: 1, Files: 2, Size: 120},
+		{Name: "scripts", Depth: 1, Files: 2, Size: 70},
+		{Name: filepath.FromSlash("scripts/prefabs"), Depth: 2, Files: 1, Size: 40},
+	}, report.Dirs)
+
+	assert.Equal(t, []SizeEntry{
+		{Name: ".lua", Files: 4, Size: 110},
+		{Name: ".tex", Files: 1, Size: 100},
+		{Name: ".xml", Files: 1, Size: 20},
+		{Name: "-", Files: 1, Size: 5},
+	}, report.Exts)
+
+	// files of the same size are sorted by name
+	assert.Equal(t, []SizeEntry{
+		{Name: filepath.FromSlash("images/icon.tex"), Files: 1, Size: 100},
+		{Name: filepath.FromSlash("scripts/prefabs/bar.lua"), Files: 1, Size: 40},
+		{Name: "modmain.lua", Files: 1, Size: 30},
+	}, report.Largest)
+
+	report, err = w.Size(-1)
+	assert.Nil(t, err)
+	assert.Len(t, report.Largest, 7)
+	assert.Equal(t, filepath.FromSlash("scripts/foo.lua"), report.Largest[3].Name)
+	assert.Equal(t, "LICENSE", report.Largest[6].Name)
+}
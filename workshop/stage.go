@@ -0,0 +1,75 @@
+package workshop
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	// StagingSuffix is appended to the destination path while it's being
+	// written.
+	StagingSuffix = ".tmp"
+
+	// BackupSuffix is appended to the previous destination path until the new
+	// one replaces it.
+	BackupSuffix = ".bak"
+)
+
+// commitStaged replaces the destination path with the staging one. The
+// previous destination is kept with BackupSuffix until the rename succeeds and
+// restored otherwise.
+func commitStaged(staging, dest string) error {
+	backup := dest + BackupSuffix
+	if err := os.RemoveAll(backup); err != nil {
+		return err
+	}
+
+	hasPrevious := false
+	if _, err := os.Lstat(dest); err == nil {
+		if err := os.Rename(dest, backup); err != nil {
+			return err
+		}
+		hasPrevious = true
+	}
+
+	if err := os.Rename(staging, dest); err != nil {
+		if hasPrevious {
+			_ = os.Rename(backup, dest)
+		}
+		return err
+	}
+
+	if hasPrevious {
+		return os.RemoveAll(backup)
+	}
+
+	return nil
+}
+
+// stageFile creates a staging file for the provided path and passes it to
+// write. On success, the staging file replaces the path using commitStaged and
+// it's removed otherwise.
+func stageFile(path string, write func(*os.File) error) error {
+	staging := path + StagingSuffix
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	f, err := os.Create(staging)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		_ = f.Close()
+		_ = os.Remove(staging)
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		_ = os.Remove(staging)
+		return err
+	}
+
+	return commitStaged(staging, path)
+}
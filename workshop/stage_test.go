@@ -0,0 +1,142 @@
+package workshop
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// assertNoLeftovers asserts that neither the staging nor the backup path of
+// the destination is left behind.
+func assertNoLeftovers(t *testing.T, dest string) {
+	for _, suffix := range []string{StagingSuffix, BackupSuffix} {
+		_, err := os.Lstat(dest + suffix)
+		assert.Truef(t, os.IsNotExist(err), "%s should be removed", dest+suffix)
+	}
+}
+
+func assertFileContent(t *testing.T, path, expected string) {
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, string(content))
+}
+
+func TestCommitStaged(t *testing.T) {
+	root := t.TempDir()
+	dest := filepath.Join(root, "workshop")
+	writeFiles(t, root, map[string]string{
+		"workshop/modinfo.lua":     "previous",
+		"workshop.tmp/modinfo.lua": "new",
+	})
+
+	assert.Nil(t, commitStaged(dest+StagingSuffix, dest))
+	assertFileContent(t, filepath.Join(dest, "modinfo.lua"), "new")
+	assertNoLeftovers(t, dest)
+}
+
+func TestCommitStaged_RenameError(t *testing.T) {
+	root := t.TempDir()
+	dest := filepath.Join(root, "workshop")
+	writeFiles(t, root, map[string]string{
+		"workshop/modinfo.lua": "previous",
+		"workshop.bak/old.lua": "stale backup",
+	})
+
+	// the staging path doesn't exist, so it can't be renamed
+	err := commitStaged(dest+StagingSuffix, dest)
+	assert.True(t, os.IsNotExist(err))
+
+	assertFileContent(t, filepath.Join(dest, "modinfo.lua"), "previous")
+	assertNoLeftovers(t, dest)
+}
+
+func TestStageFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "workshop.zip")
+
+	for _, content := range []string{"first", "second"} {
+		assert.Nil(t, stageFile(path, func(f *os.File) error {
+			_, err := f.WriteString(content)
+			return err
+		}))
+		assertFileContent(t, path, content)
+		assertNoLeftovers(t, path)
+	}
+}
+
+func TestStageFile_WriteError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workshop.zip")
+	assert.Nil(t, os.WriteFile(path, []byte("previous"), 0o600))
+
+	expected := errors.New("failed")
+	err := stageFile(path, func(f *os.File) error {
+		_, _ = f.WriteString("partial")
+		return expected
+	})
+
+	assert.Equal(t, expected, err)
+	assertFileContent(t, path, "previous")
+	assertNoLeftovers(t, path)
+}
+
+func TestStageFile_ReadOnlyDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are ignored for root")
+	}
+
+	root := filepath.Join(t.TempDir(), "out")
+	path := filepath.Join(root, "workshop.zip")
+	assert.Nil(t, os.MkdirAll(root, 0o755))
+	assert.Nil(t, os.WriteFile(path, []byte("previous"), 0o600))
+
+	assert.Nil(t, os.Chmod(root, 0o555))
+	t.Cleanup(func() {
+		_ = os.Chmod(root, 0o755)
+	})
+
+	called := false
+	err := stageFile(path, func(f *os.File) error {
+		called = true
+		return nil
+	})
+
+	assert.True(t, os.IsPermission(err))
+	assert.False(t, called)
+	assertFileContent(t, path, "previous")
+	assertNoLeftovers(t, path)
+}
+
+func TestWorkshop_CopyFiles_Error(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, packageFiles)
+
+	w := newWorkshop(t, src, FormatDir, 1)
+	build(t, w)
+
+	writeFiles(t, src, map[string]string{"modmain.lua": "changed"})
+	assert.Nil(t, os.Remove(filepath.Join(src, "scripts", "foo.lua")))
+
+	assert.NotNil(t, w.CopyFiles())
+	assertFileContent(t, filepath.Join(w.AbsDestPath(), "modmain.lua"), packageFiles["modmain.lua"])
+	assertFileContent(t, filepath.Join(w.AbsDestPath(), "scripts", "foo.lua"), packageFiles["scripts/foo.lua"])
+	assertNoLeftovers(t, w.AbsDestPath())
+}
+
+func TestWorkshop_ZipFiles_Error(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, packageFiles)
+
+	w := newWorkshop(t, src, FormatZip, 1)
+	path := build(t, w)
+
+	previous, err := os.ReadFile(path)
+	assert.Nil(t, err)
+
+	assert.Nil(t, os.Remove(filepath.Join(src, "scripts", "foo.lua")))
+
+	assert.NotNil(t, w.ZipFiles())
+	assertFileContent(t, path, string(previous))
+	assertNoLeftovers(t, path)
+}
@@ -0,0 +1,96 @@
+package workshop
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/dstmodders/mod-cli/dir"
+)
+
+const regexPlaceholder string = `\{\{\s*([A-Za-z0-9_]+)\s*\}\}`
+
+var placeholderRegex = regexp.MustCompile(regexPlaceholder)
+
+// Transform represents a transform stage applied to the packaged copy of a
+// file. It receives a path relative to the package root along with the file
+// content and returns the new content. Sources are never modified.
+type Transform func(name string, data []byte) ([]byte, error)
+
+// MinifyTransform returns a Transform which strips comments, including LDoc
+// ones, from Lua files using MinifyLua. Other files are left untouched.
+func MinifyTransform(keepLines, whitespace bool) Transform {
+	return func(name string, data []byte) ([]byte, error) {
+		if !strings.EqualFold(filepath.Ext(name), ".lua") {
+			return data, nil
+		}
+		return MinifyLua(data, keepLines, whitespace), nil
+	}
+}
+
+// TemplateTransform returns a Transform which replaces placeholders like
+// "{{version}}" with values from vars in files matching any of the provided
+// patterns. Patterns are matched the same way as ignore ones. Unknown
+// placeholders are left untouched.
+func TemplateTransform(patterns []string, vars map[string]string) Transform {
+	return func(name string, data []byte) ([]byte, error) {
+		matched := false
+		for _, pattern := range patterns {
+			if dir.MatchPath(pattern, filepath.ToSlash(name)) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return data, nil
+		}
+
+		return placeholderRegex.ReplaceAllFunc(data, func(match []byte) []byte {
+			key := string(placeholderRegex.FindSubmatch(match)[1])
+			if val, ok := vars[key]; ok {
+				return []byte(val)
+			}
+			return match
+		}), nil
+	}
+}
+
+// AddTransform adds a transform stage applied in order to each file by
+// CopyFiles, ZipFiles and TarFiles.
+func (w *Workshop) AddTransform(t Transform) {
+	w.transforms = append(w.transforms, t)
+}
+
+// Transforms gets transform stages added using AddTransform.
+func (w *Workshop) Transforms() []Transform {
+	return w.transforms
+}
+
+// openFile opens the source of the provided file and applies all transform
+// stages. It returns a reader along with the resulting size.
+func (w *Workshop) openFile(name string, stat os.FileInfo) (io.ReadCloser, int64, error) {
+	if len(w.transforms) == 0 {
+		src, err := os.Open(w.SourcePath(name))
+		if err != nil {
+			return nil, 0, err
+		}
+		return src, stat.Size(), nil
+	}
+
+	data, err := os.ReadFile(w.SourcePath(name))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, t := range w.transforms {
+		if data, err = t(name, data); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+}
@@ -0,0 +1,271 @@
+package workshop
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dstmodders/mod-cli/modinfo"
+)
+
+// EntryPoints holds mod files loaded by the game itself.
+var EntryPoints = []string{
+	"modmain.lua",
+	"modworldgenmain.lua",
+}
+
+// ValidationError represents a single validation error.
+type ValidationError struct {
+	// Check holds a name of the check that has failed.
+	Check string
+
+	// Message holds the error description.
+	Message string
+}
+
+// Error returns a string representation of a ValidationError.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Check, e.Message)
+}
+
+func newValidationError(check, format string, a ...interface{}) *ValidationError {
+	return &ValidationError{
+		Check:   check,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+// SetMaxSize sets the maximum total size of files in bytes after all transform
+// stages. Zero disables the check.
+func (w *Workshop) SetMaxSize(size int64) {
+	w.maxSize = size
+}
+
+// MaxSize gets the maximum total size of files in bytes.
+func (w *Workshop) MaxSize() int64 {
+	return w.maxSize
+}
+
+// HasFile checks if the provided path is among files retrieved earlier using
+// GetFiles.
+func (w *Workshop) HasFile(name string) bool {
+	name = filepath.Clean(name)
+	for _, file := range w.files {
+		if file == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks files retrieved earlier using GetFiles to make sure that they
+// can be published: required mod info fields are present, the icon is
+// included, no required Lua files are ignored, file names are compatible with
+// case-insensitive filesystems and the total size is within the limit.
+func (w *Workshop) Validate(info *modinfo.ModInfo) (result []*ValidationError) {
+	result = append(result, w.validateModInfo(info)...)
+	result = append(result, w.validateIcon(info)...)
+	result = append(result, w.validateRequires()...)
+	result = append(result, w.validateNames()...)
+	result = append(result, w.validateSize()...)
+	return result
+}
+
+func (w *Workshop) validateModInfo(info *modinfo.ModInfo) (result []*ValidationError) {
+	if !w.HasFile("modinfo.lua") {
+		result = append(result, newValidationError("modinfo", "modinfo.lua is not included"))
+	}
+
+	if info == nil {
+		return result
+	}
+
+	var fields []*modinfo.Field
+	for _, field := range info.General {
+		fields = append(fields, field)
+	}
+
+	for _, field := range info.Compatibility {
+		fields = append(fields, field)
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Name < fields[j].Name
+	})
+
+	for _, field := range fields {
+		if !field.IsRequired {
+			continue
+		}
+
+		switch val := field.Value.(type) {
+		case nil:
+		case string:
+			if len(val) > 0 {
+				continue
+			}
+		case int:
+			if val != 0 {
+				continue
+			}
+		default:
+			continue
+		}
+
+		result = append(result, newValidationError("modinfo", "required field %s is missing", field.Name))
+	}
+
+	return result
+}
+
+func (w *Workshop) validateIcon(info *modinfo.ModInfo) (result []*ValidationError) {
+	names := map[string]string{
+		"icon":       "modicon.tex",
+		"icon_atlas": "modicon.xml",
+	}
+
+	for _, field := range []string{"icon_atlas", "icon"} {
+		name := names[field]
+
+		if info != nil {
+			if f, err := info.FieldByName(field); err == nil && f != nil {
+				if val, ok := f.Value.(string); ok && len(val) > 0 {
+					name = val
+				}
+			}
+		}
+
+		if !w.HasFile(name) {
+			result = append(result, newValidationError("icon", "%s is not included", name))
+		}
+	}
+
+	return result
+}
+
+func (w *Workshop) validateRequires() (result []*ValidationError) {
+	visited := map[string]bool{}
+	queue := append([]string{}, EntryPoints...)
+
+	for len(queue) > 0 {
+		file := queue[0]
+		queue = queue[1:]
+
+		if visited[file] || !w.HasFile(file) {
+			continue
+		}
+		visited[file] = true
+
+		refs, err := ParseRefs(w.SourcePath(file))
+		if err != nil {
+			result = append(result, newValidationError("scripts", "%s", err.Error()))
+			continue
+		}
+
+		for _, ref := range refs {
+			if !ref.IsLua() || visited[ref.Path] {
+				continue
+			}
+
+			if _, err := os.Stat(ref.Path); err != nil {
+				continue
+			}
+
+			if !w.HasFile(ref.Path) {
+				result = append(result, newValidationError(
+					"scripts",
+					"%s is loaded by %s in %s but ignored",
+					ref.Path,
+					ref.Kind,
+					file,
+				))
+				visited[ref.Path] = true
+				continue
+			}
+
+			queue = append(queue, ref.Path)
+		}
+	}
+
+	return result
+}
+
+// validateNames checks every path prefix, so both files and directories that
+// only differ in case are reported, each of them once.
+func (w *Workshop) validateNames() (result []*ValidationError) {
+	seen := map[string]string{}
+	reported := map[string]bool{}
+	for _, file := range w.files {
+		parts := strings.Split(filepath.ToSlash(file), "/")
+		for i := range parts {
+			path := strings.Join(parts[:i+1], "/")
+			lower := strings.ToLower(path)
+
+			other, ok := seen[lower]
+			if !ok {
+				seen[lower] = path
+				continue
+			}
+
+			if other == path {
+				continue
+			}
+
+			if !reported[path] {
+				reported[path] = true
+				result = append(result, newValidationError(
+					"names",
+					"%s and %s only differ in case",
+					filepath.FromSlash(other),
+					filepath.FromSlash(path),
+				))
+			}
+			break
+		}
+	}
+	return result
+}
+
+// packedSize returns the total size of files with all transform stages
+// applied, the same as in the archive or the destination directory.
+func (w *Workshop) packedSize() (size int64, err error) {
+	for _, file := range w.files {
+		stat, err := w.statFile(file)
+		if err != nil {
+			return 0, err
+		}
+
+		src, n, err := w.openFile(file, stat)
+		if err != nil {
+			return 0, err
+		}
+		_ = src.Close()
+
+		size += n
+	}
+	return size, nil
+}
+
+func (w *Workshop) validateSize() (result []*ValidationError) {
+	if w.maxSize <= 0 {
+		return result
+	}
+
+	size, err := w.packedSize()
+	if err != nil {
+		return append(result, newValidationError("size", "failed to get the total size: %s", err))
+	}
+
+	if size > w.maxSize {
+		result = append(result, newValidationError(
+			"size",
+			"total size of %d bytes exceeds the limit of %d bytes",
+			size,
+			w.maxSize,
+		))
+	}
+
+	return result
+}
@@ -0,0 +1,204 @@
+package workshop
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dstmodders/mod-cli/modinfo"
+	"github.com/stretchr/testify/assert"
+)
+
+// newIgnoreWorkshop creates a Workshop for the source directory with files
+// retrieved using GetFiles after setting the ignore list.
+func newIgnoreWorkshop(t *testing.T, src string, ignore []string) *Workshop {
+	chdir(t, src)
+
+	w, err := New(src, filepath.Join(t.TempDir(), "workshop"))
+	assert.Nil(t, err)
+	w.SetIgnore(ignore)
+
+	_, _, err = w.GetFiles()
+	assert.Nil(t, err)
+
+	return w
+}
+
+func loadModInfo(t *testing.T, src string) *modinfo.ModInfo {
+	info := modinfo.New()
+	assert.Nil(t, info.LoadString(src))
+	return info
+}
+
+func TestWorkshop_validateModInfo(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{"modinfo.lua": "", "modmain.lua": ""})
+
+	w := newWorkshop(t, src, FormatZip, 1)
+	assert.Empty(t, w.validateModInfo(nil))
+
+	info := loadModInfo(t, `
+name = "Test"
+description = ""
+version = "1.0.0"
+dst_compatible = true
+`)
+
+	assert.Equal(t, []*ValidationError{
+		newValidationError("modinfo", "required field api_version is missing"),
+		newValidationError("modinfo", "required field author is missing"),
+		newValidationError("modinfo", "required field description is missing"),
+	}, w.validateModInfo(info))
+
+	info = loadModInfo(t, `
+name = "Test"
+author = "Author"
+description = "Description"
+version = "1.0.0"
+api_version = 10
+`)
+	assert.Empty(t, w.validateModInfo(info))
+
+	w = newIgnoreWorkshop(t, src, []string{"modinfo.lua"})
+	assert.Equal(t, []*ValidationError{
+		newValidationError("modinfo", "modinfo.lua is not included"),
+	}, w.validateModInfo(nil))
+}
+
+func TestWorkshop_validateIcon(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"modinfo.lua":      "",
+		"modicon.tex":      "tex",
+		"modicon.xml":      "xml",
+		"images/icon.tex":  "tex",
+		"images/other.xml": "xml",
+	})
+
+	w := newWorkshop(t, src, FormatZip, 1)
+	assert.Empty(t, w.validateIcon(nil), "default icon")
+
+	info := loadModInfo(t, `icon_atlas = "images/icon.xml"
+icon = "images/icon.tex"`)
+	assert.Equal(t, []*ValidationError{
+		newValidationError("icon", "images/icon.xml is not included"),
+	}, w.validateIcon(info), "missing .xml")
+
+	w = newIgnoreWorkshop(t, src, []string{"*.tex"})
+	assert.Equal(t, []*ValidationError{
+		newValidationError("icon", "modicon.tex is not included"),
+	}, w.validateIcon(nil), "missing .tex")
+}
+
+func TestWorkshop_validateRequires(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"modmain.lua":            `require("foo"); require("missing")`,
+		"modworldgenmain.lua":    `modimport("worldgen")`,
+		"worldgen.lua":           `require("gen.rooms"); require("foo")`,
+		"scripts/foo.lua":        `require("bar"); require("foo")`,
+		"scripts/bar.lua":        `return {}`,
+		"scripts/gen/rooms.lua":  `return {}`,
+		"scripts/unused.lua":     `require("unused_dep")`,
+		"scripts/unused_dep.lua": `return {}`,
+	})
+
+	w := newWorkshop(t, src, FormatZip, 1)
+	assert.Empty(t, w.validateRequires())
+
+	w = newIgnoreWorkshop(t, src, []string{"bar.lua", "gen/", "unused_dep.lua"})
+	assert.Equal(t, []*ValidationError{
+		newValidationError("scripts", "%s is loaded by require in %s but ignored",
+			filepath.FromSlash("scripts/bar.lua"),
+			filepath.FromSlash("scripts/foo.lua"),
+		),
+		newValidationError("scripts", "%s is loaded by require in %s but ignored",
+			filepath.FromSlash("scripts/gen/rooms.lua"),
+			"worldgen.lua",
+		),
+	}, w.validateRequires())
+
+	w = newIgnoreWorkshop(t, src, []string{"worldgen.lua"})
+	assert.Equal(t, []*ValidationError{
+		newValidationError("scripts", "worldgen.lua is loaded by modimport in modworldgenmain.lua but ignored"),
+	}, w.validateRequires(), "files loaded only by ignored ones aren't reported")
+}
+
+func TestWorkshop_validateNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		expected []string
+	}{
+		{"no collisions", []string{"modmain.lua", "scripts/a.lua", "scripts/b.lua"}, nil},
+		{
+			"files",
+			[]string{"scripts/a.lua", "scripts/A.lua"},
+			[]string{"scripts/a.lua and scripts/A.lua only differ in case"},
+		},
+		{
+			"directories",
+			[]string{"Scripts/a.lua", "scripts/b.lua", "Scripts/c.lua", "scripts/d.lua"},
+			[]string{"Scripts and scripts only differ in case"},
+		},
+		{
+			"nested directories",
+			[]string{"scripts/Foo/a.lua", "scripts/foo/a.lua", "scripts/a.lua"},
+			[]string{"scripts/Foo and scripts/foo only differ in case"},
+		},
+		{
+			"file and directory",
+			[]string{"scripts/foo", "scripts/Foo/a.lua"},
+			[]string{"scripts/foo and scripts/Foo only differ in case"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &Workshop{}
+			for _, file := range tt.files {
+				w.files = append(w.files, filepath.FromSlash(file))
+			}
+
+			var messages []string
+			for _, err := range w.validateNames() {
+				assert.Equal(t, "names", err.Check)
+				messages = append(messages, filepath.ToSlash(err.Message))
+			}
+			assert.Equal(t, tt.expected, messages)
+		})
+	}
+}
+
+func TestWorkshop_validateSize(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"modinfo.lua": strings.Repeat("a", 40),
+		"modmain.lua": strings.Repeat("a", 60),
+	})
+
+	w := newWorkshop(t, src, FormatZip, 1)
+	assert.Empty(t, w.validateSize(), "no limit")
+
+	w.SetMaxSize(100)
+	assert.Empty(t, w.validateSize())
+
+	w.SetMaxSize(99)
+	assert.Equal(t, []*ValidationError{
+		newValidationError("size", "total size of 100 bytes exceeds the limit of 99 bytes"),
+	}, w.validateSize())
+
+	// the size after transforms is validated
+	w.AddTransform(func(name string, data []byte) ([]byte, error) {
+		return data[:len(data)/2], nil
+	})
+	assert.Empty(t, w.validateSize())
+
+	w.AddTransform(func(name string, data []byte) ([]byte, error) {
+		return bytes.Repeat(data, 4), nil
+	})
+	assert.Equal(t, []*ValidationError{
+		newValidationError("size", "total size of 200 bytes exceeds the limit of 99 bytes"),
+	}, w.validateSize())
+}
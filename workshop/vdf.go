@@ -0,0 +1,124 @@
+package workshop
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// AppID is the Steam application ID of Don't Starve Together.
+const AppID = 322330
+
+const regexPublishedFileID string = `"publishedfileid"\s+"(\d+)"`
+
+var publishedFileIDRegex = regexp.MustCompile(regexPublishedFileID)
+
+// Item represents a Steam Workshop item to be built by SteamCMD using
+// "workshop_build_item":
+// https://partner.steamgames.com/doc/features/workshop/implementation#SteamCmd
+type Item struct {
+	// AppID holds the Steam application ID.
+	//
+	// Default: 322330
+	AppID int
+
+	// PublishedFileID holds the ID of an existing item to update. An empty value
+	// or "0" creates a new item.
+	PublishedFileID string
+
+	// ContentFolder holds an absolute path to the directory with item files.
+	ContentFolder string
+
+	// PreviewFile holds an absolute path to the preview image.
+	PreviewFile string
+
+	// Visibility holds the item visibility: 0 (public), 1 (friends only), 2
+	// (private) or 3 (unlisted).
+	Visibility int
+
+	// Title holds the item title.
+	Title string
+
+	// Description holds the item description.
+	Description string
+
+	// ChangeNote holds the change note for this update.
+	ChangeNote string
+}
+
+// NewItem creates a new Item instance.
+func NewItem() *Item {
+	return &Item{
+		AppID:           AppID,
+		PublishedFileID: "0",
+	}
+}
+
+func vdfEscape(str string) string {
+	str = strings.ReplaceAll(str, `\`, `\\`)
+	str = strings.ReplaceAll(str, `"`, `\"`)
+	return str
+}
+
+// VDF returns a string representation of an Item in a Valve Data Format.
+func (i *Item) VDF() string {
+	var b strings.Builder
+
+	id := i.PublishedFileID
+	if len(id) == 0 {
+		id = "0"
+	}
+
+	field := func(name, value string) {
+		b.WriteString(fmt.Sprintf("\t\"%s\" \"%s\"\n", name, vdfEscape(value)))
+	}
+
+	b.WriteString("\"workshopitem\"\n{\n")
+	field("appid", fmt.Sprint(i.AppID))
+	field("publishedfileid", id)
+	field("contentfolder", i.ContentFolder)
+
+	if len(i.PreviewFile) > 0 {
+		field("previewfile", i.PreviewFile)
+	}
+
+	field("visibility", fmt.Sprint(i.Visibility))
+
+	if len(i.Title) > 0 {
+		field("title", i.Title)
+	}
+
+	if len(i.Description) > 0 {
+		field("description", i.Description)
+	}
+
+	if len(i.ChangeNote) > 0 {
+		field("changenote", i.ChangeNote)
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// WriteVDF writes an Item in a Valve Data Format to the provided path.
+func (i *Item) WriteVDF(path string) error {
+	return os.WriteFile(path, []byte(i.VDF()), 0644)
+}
+
+// ReadPublishedFileID reads the published file ID from the provided VDF file.
+// SteamCMD writes it back after creating a new item.
+func ReadPublishedFileID(path string) (string, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	match := publishedFileIDRegex.FindStringSubmatch(string(src))
+	if len(match) != 2 {
+		return "", fmt.Errorf("publishedfileid not found in %s", path)
+	}
+
+	return match[1], nil
+}
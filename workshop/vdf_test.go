@@ -0,0 +1,111 @@
+package workshop
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewItem(t *testing.T) {
+	i := NewItem()
+	assert.Equal(t, AppID, i.AppID)
+	assert.Equal(t, "0", i.PublishedFileID)
+}
+
+func TestItem_VDF(t *testing.T) {
+	i := NewItem()
+	i.PublishedFileID = ""
+	i.ContentFolder = "/tmp/mod"
+
+	assert.Equal(t, `"workshopitem"
+{
+	"appid" "322330"
+	"publishedfileid" "0"
+	"contentfolder" "/tmp/mod"
+	"visibility" "0"
+}
+`, i.VDF())
+
+	i.PublishedFileID = "1234567890"
+	i.PreviewFile = "/tmp/preview.gif"
+	i.Visibility = 2
+	i.Title = "Title"
+	i.Description = "Line\nAnother line"
+	i.ChangeNote = "Fixed"
+
+	assert.Equal(t, `"workshopitem"
+{
+	"appid" "322330"
+	"publishedfileid" "1234567890"
+	"contentfolder" "/tmp/mod"
+	"previewfile" "/tmp/preview.gif"
+	"visibility" "2"
+	"title" "Title"
+	"description" "Line
+Another line"
+	"changenote" "Fixed"
+}
+`, i.VDF())
+}
+
+func TestVDFEscape(t *testing.T) {
+	testCases := map[string]string{
+		``:                    ``,
+		`Title`:               `Title`,
+		`"Quoted" title`:      `\"Quoted\" title`,
+		`C:\mods\title`:       `C:\\mods\\title`,
+		`\"`:                  `\\\"`,
+		`[b]Bold[/b] "ü" ${}`: `[b]Bold[/b] \"ü\" ${}`,
+	}
+
+	for str, expected := range testCases {
+		assert.Equalf(t, expected, vdfEscape(str), "String %q", str)
+	}
+
+	i := NewItem()
+	i.Title = `My "Mod" \ Test`
+	i.Description = `Say "hi"`
+	assert.Contains(t, i.VDF(), "\t\"title\" \"My \\\"Mod\\\" \\\\ Test\"\n")
+	assert.Contains(t, i.VDF(), "\t\"description\" \"Say \\\"hi\\\"\"\n")
+}
+
+func TestReadPublishedFileID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "item.vdf")
+
+	i := NewItem()
+	i.Title = `"publishedfileid" "42"`
+	assert.Nil(t, i.WriteVDF(path))
+
+	id, err := ReadPublishedFileID(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "0", id)
+
+	i.PublishedFileID = "1234567890"
+	assert.Nil(t, i.WriteVDF(path))
+
+	id, err = ReadPublishedFileID(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "1234567890", id)
+
+	// SteamCMD writes the file back using tabs as separators
+	assert.Nil(t, os.WriteFile(path, []byte("\"workshopitem\"\n{\n\t\"appid\"\t\t\"322330\"\n\t\"publishedfileid\"\t\t\"987654321\"\n}\n"), 0o600))
+
+	id, err = ReadPublishedFileID(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "987654321", id)
+}
+
+func TestReadPublishedFileID_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := ReadPublishedFileID(filepath.Join(dir, "missing.vdf"))
+	assert.True(t, os.IsNotExist(err))
+
+	path := filepath.Join(dir, "item.vdf")
+	assert.Nil(t, os.WriteFile(path, []byte("\"workshopitem\"\n{\n}\n"), 0o600))
+
+	_, err = ReadPublishedFileID(path)
+	assert.EqualError(t, err, "publishedfileid not found in "+path)
+}
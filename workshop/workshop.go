@@ -1,30 +1,72 @@
 // Package workshop has been designed to prepare a mod directory or archive for
 // Steam Workshop. It allows including only the essential files based on ignore
-// list. In the future, it may also include the features to automatically
-// publish your mod.
+// list and generating a VDF file to publish your mod using SteamCMD.
 package workshop
 
 import (
+	"archive/tar"
 	"archive/zip"
+	"compress/flate"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
 
 	"github.com/dstmodders/mod-cli/dir"
+	"github.com/dstmodders/mod-cli/modinfo"
+)
+
+const (
+	zipVersion20 = 20    // 2.0, the version CreateHeader uses
+	zipFlagUTF8  = 0x800 // name and comment are encoded in UTF-8
 )
 
 // Controller is the interface that wraps the Workshop methods.
 type Controller interface {
 	SetIgnore([]string)
+	SetInclude([]string)
+	SetReproducible(bool)
+	Reproducible() bool
+	SetModTime(time.Time)
+	ModTime() time.Time
+	SetCompressionLevel(int) error
+	SetFormat(Format)
+	Format() Format
+	SetJobs(int)
+	Jobs() int
+	SetProgress(ProgressFunc)
+	SetMaxSize(int64)
+	MaxSize() int64
 	IsPathIgnored(string) bool
 	GetFiles() ([]string, int64, error)
+	AddFile(string, string) error
+	SourcePath(string) string
+	AddTransform(Transform)
+	Transforms() []Transform
+	HasFile(string) bool
+	Validate(*modinfo.ModInfo) []*ValidationError
+	Analyze(*modinfo.ModInfo) (*Analysis, error)
+	Diff(*Package) (*PackageDiff, error)
+	Size(int) (*SizeReport, error)
 	DestDirExists() bool
 	MakeDestDir() error
 	MakeDestFile(string) error
 	CopyFiles() error
 	ZipFiles() error
+	TarFiles() error
+	Install(string, bool) error
+	DestExists() bool
+	ArchivePath() string
+	ArchiveChecksum() (string, error)
+	VDFPath() string
 	CountDestItems() (int, error)
 	Files() []string
 	FilesSize() int64
@@ -37,12 +79,92 @@ type Controller interface {
 
 // Workshop represents a workshop-related data.
 type Workshop struct {
-	files       []string
-	filesSize   int64
-	srcDir      dir.Dir
-	relDestPath string
-	absDestPath string
-	destDirName string
+	files            []string
+	filesSize        int64
+	sources          map[string]string
+	srcDir           dir.Dir
+	relDestPath      string
+	absDestPath      string
+	destDirName      string
+	reproducible     bool
+	modTime          time.Time
+	compressionLevel int
+	format           Format
+	maxSize          int64
+	transforms       []Transform
+	jobs             int
+	progress         ProgressFunc
+}
+
+// Format represents an output format.
+type Format int
+
+const (
+	// FormatDir represents a directory.
+	FormatDir Format = iota
+
+	// FormatZip represents a ZIP archive.
+	FormatZip
+
+	// FormatTarGz represents a gzipped tarball.
+	FormatTarGz
+)
+
+// Formats holds string representations of all supported formats.
+var Formats = []string{"dir", "zip", "tar.gz"}
+
+// ParseFormat parses a format from its string representation: "dir", "zip" or
+// "tar.gz".
+func ParseFormat(str string) (Format, error) {
+	for i, f := range Formats {
+		if f == str {
+			return Format(i), nil
+		}
+	}
+	return FormatDir, fmt.Errorf("unsupported format: %s", str)
+}
+
+// String returns a string representation of a Format.
+func (f Format) String() string {
+	if int(f) >= 0 && int(f) < len(Formats) {
+		return Formats[f]
+	}
+	return "-"
+}
+
+// Ext returns a file extension of a Format. It's empty for a directory.
+func (f Format) Ext() string {
+	if f == FormatDir {
+		return ""
+	}
+	return "." + f.String()
+}
+
+// IsArchive checks if a Format represents an archive.
+func (f Format) IsArchive() bool {
+	return f != FormatDir
+}
+
+// DefaultModTime is the modification time used for all archive entries in a
+// reproducible mode unless overridden. It's the earliest time the ZIP format
+// can represent.
+var DefaultModTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// ModTimeFromEnv returns the modification time based on the
+// SOURCE_DATE_EPOCH environment variable or DefaultModTime if it's not set:
+// https://reproducible-builds.org/specs/source-date-epoch/
+func ModTimeFromEnv() (time.Time, error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if len(epoch) == 0 {
+		return DefaultModTime, nil
+	}
+
+	sec, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return DefaultModTime, fmt.Errorf("invalid SOURCE_DATE_EPOCH value: %s", epoch)
+	}
+
+	return time.Unix(sec, 0).UTC(), nil
 }
 
 // New creates a new Workshop instance.
@@ -65,10 +187,12 @@ func New(src, dest string) (*Workshop, error) {
 	destDirName := filepath.Base(relDestPath)
 
 	return &Workshop{
-		srcDir:      *srcDir,
-		absDestPath: absDestPath,
-		destDirName: destDirName,
-		relDestPath: relDestPath,
+		srcDir:           *srcDir,
+		absDestPath:      absDestPath,
+		destDirName:      destDirName,
+		relDestPath:      relDestPath,
+		modTime:          DefaultModTime,
+		compressionLevel: flate.DefaultCompression,
 	}, nil
 }
 
@@ -77,6 +201,55 @@ func (w *Workshop) SetIgnore(ignore []string) {
 	w.srcDir.SetIgnore(ignore)
 }
 
+// SetInclude sets include list. Paths matching it are never ignored.
+func (w *Workshop) SetInclude(include []string) {
+	w.srcDir.SetInclude(include)
+}
+
+// SetReproducible sets whether an archive should be reproducible: entries are
+// sorted and have the same modification time and permissions regardless of the
+// local filesystem.
+func (w *Workshop) SetReproducible(reproducible bool) {
+	w.reproducible = reproducible
+}
+
+// Reproducible checks whether an archive should be reproducible.
+func (w *Workshop) Reproducible() bool {
+	return w.reproducible
+}
+
+// SetModTime sets the modification time of archive entries in a reproducible
+// mode.
+func (w *Workshop) SetModTime(modTime time.Time) {
+	w.modTime = modTime
+}
+
+// ModTime gets the modification time of archive entries in a reproducible
+// mode.
+func (w *Workshop) ModTime() time.Time {
+	return w.modTime
+}
+
+// SetCompressionLevel sets the archive compression level: from 0 (no
+// compression) to 9 (best compression) or -1 (default compression).
+func (w *Workshop) SetCompressionLevel(level int) error {
+	if level < flate.DefaultCompression || level > flate.BestCompression {
+		return fmt.Errorf("invalid compression level: %d", level)
+	}
+	w.compressionLevel = level
+	return nil
+}
+
+// SetFormat sets the output format.
+func (w *Workshop) SetFormat(format Format) {
+	w.format = format
+}
+
+// Format gets the output format.
+func (w *Workshop) Format() Format {
+	return w.format
+}
+
 // IsPathIgnored checks if the provided path is ignored.
 func (w *Workshop) IsPathIgnored(path string) bool {
 	return w.srcDir.IsPathIgnored(path)
@@ -88,9 +261,53 @@ func (w *Workshop) GetFiles() ([]string, int64, error) {
 	files, size, err := w.srcDir.ListFiles()
 	w.files = files
 	w.filesSize = size
+	w.sources = nil
 	return files, size, err
 }
 
+// AddFile adds a file which is not in the source directory, like a generated
+// one, to files retrieved earlier using GetFiles. The name is a path within the
+// destination and the src is a path to the file itself.
+func (w *Workshop) AddFile(name, src string) error {
+	stat, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if !stat.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", src)
+	}
+
+	name = filepath.Clean(name)
+	if w.sources == nil {
+		w.sources = map[string]string{}
+	}
+
+	if w.HasFile(name) {
+		if prev, err := os.Stat(w.SourcePath(name)); err == nil {
+			w.filesSize -= prev.Size()
+		}
+	} else {
+		w.files = append(w.files, name)
+		sort.Strings(w.files)
+	}
+
+	w.sources[name] = src
+	w.filesSize += stat.Size()
+
+	return nil
+}
+
+// SourcePath gets a path of the file to read for the provided name from files
+// retrieved earlier using GetFiles. It's the name itself unless the file has
+// been added using AddFile.
+func (w *Workshop) SourcePath(name string) string {
+	if src, ok := w.sources[name]; ok {
+		return src
+	}
+	return name
+}
+
 // DestDirExists checks if destination directory exists.
 func (w *Workshop) DestDirExists() bool {
 	stat, _ := os.Stat(w.absDestPath)
@@ -108,101 +325,274 @@ func (w *Workshop) MakeDestFile(name string) error {
 }
 
 // CopyFiles copies all files retrieved earlier using GetFiles to the
-// destination path.
+// destination path. Files are copied into a staging directory first, which
+// replaces the destination only when all files are copied.
 func (w *Workshop) CopyFiles() error {
 	if len(w.files) == 0 {
 		return errors.New("no files to copy")
 	}
 
-	for _, file := range w.files {
-		stat, err := os.Stat(file)
-		if err != nil {
-			return err
-		}
+	staging := w.absDestPath + StagingSuffix
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
 
-		if !stat.Mode().IsRegular() {
-			return fmt.Errorf("%s is not a regular file", stat.Name())
+	copyFile := func(file string) (*packedFile, error) {
+		stat, err := w.statFile(file)
+		if err != nil {
+			return nil, err
 		}
 
-		src, err := os.Open(file)
+		src, _, err := w.openFile(file, stat)
 		if err != nil {
-			_ = src.Close()
-			return err
+			return nil, err
 		}
+		defer src.Close()
 
-		if err := w.MakeDestFile(src.Name()); err != nil {
-			return err
+		path := filepath.Join(staging, file)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return nil, err
 		}
 
-		dest, err := os.Create(filepath.Join(w.relDestPath, src.Name()))
+		dest, err := os.Create(path)
 		if err != nil {
-			_ = src.Close()
-			return err
+			return nil, err
 		}
 
-		_, err = io.Copy(dest, src)
-		if err != nil {
-			_ = src.Close()
+		if _, err := io.Copy(dest, src); err != nil {
 			_ = dest.Close()
-			return err
+			return nil, err
 		}
 
-		_ = src.Close()
-		_ = dest.Close()
+		return &packedFile{name: file, stat: stat}, dest.Close()
 	}
 
-	return nil
+	if err := w.forEachFile(w.files, copyFile, func(*packedFile) error {
+		return nil
+	}); err != nil {
+		_ = os.RemoveAll(staging)
+		return err
+	}
+
+	return commitStaged(staging, w.absDestPath)
+}
+
+func (w *Workshop) zipHeader(file string, stat os.FileInfo) (*zip.FileHeader, error) {
+	header, err := zip.FileInfoHeader(stat)
+	if err != nil {
+		return nil, err
+	}
+
+	header.Name = filepath.ToSlash(file)
+	header.Method = zip.Deflate
+	if w.compressionLevel == flate.NoCompression {
+		header.Method = zip.Store
+	}
+
+	if w.reproducible {
+		header.Modified = w.modTime
+		header.SetMode(0644)
+	}
+
+	// unlike CreateHeader, CreateRaw writes the header as is, so the MS-DOS
+	// time, versions and UTF-8 flag are set here the same way
+	header.ModifiedDate, header.ModifiedTime = msDosTime(header.Modified)
+	header.ReaderVersion = zipVersion20
+	header.CreatorVersion = header.CreatorVersion&0xff00 | zipVersion20
+	if requiresUTF8(header.Name) {
+		header.Flags |= zipFlagUTF8
+	}
+
+	return header, nil
+}
+
+// requiresUTF8 checks if the name is a valid UTF-8 string which can't be
+// represented as ASCII, so it needs the UTF-8 flag in a ZIP archive.
+func requiresUTF8(name string) bool {
+	if !utf8.ValidString(name) {
+		return false
+	}
+
+	for i := 0; i < len(name); i++ {
+		if name[i] >= utf8.RuneSelf {
+			return true
+		}
+	}
+
+	return false
+}
+
+// msDosTime converts a time into the MS-DOS date and time used by ZIP archives.
+// Times before DefaultModTime can't be represented, so it's used instead.
+func msDosTime(t time.Time) (date uint16, clock uint16) {
+	if t.Before(DefaultModTime) {
+		t = DefaultModTime
+	}
+
+	date = uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9)
+	clock = uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)
+
+	return date, clock
+}
+
+// sortedFiles returns files retrieved earlier using GetFiles sorted by name
+// when in a reproducible mode.
+func (w *Workshop) sortedFiles() []string {
+	if !w.reproducible {
+		return w.files
+	}
+
+	files := make([]string, len(w.files))
+	copy(files, w.files)
+	sort.Strings(files)
+
+	return files
 }
 
 // ZipFiles create an archive of all files retrieved earlier using GetFiles.
+// Files are compressed in parallel but always written in the same order. The
+// archive is written into a staging file first, which replaces the previous
+// one only when complete.
 func (w *Workshop) ZipFiles() error {
 	if len(w.files) == 0 {
 		return errors.New("no files to zip")
 	}
 
-	archive, err := os.Create(w.relDestPath + ".zip")
-	if err != nil {
-		return err
-	}
+	return stageFile(w.absDestPath+FormatZip.Ext(), func(archive *os.File) error {
+		zipWriter := zip.NewWriter(archive)
 
-	zipWriter := zip.NewWriter(archive)
-	defer func(archive *os.File, zipWriter *zip.Writer) {
-		_ = archive.Close()
-		_ = zipWriter.Close()
-	}(archive, zipWriter)
+		if err := w.forEachFile(w.sortedFiles(), w.compressFile, func(f *packedFile) error {
+			header, err := w.zipHeader(f.name, f.stat)
+			if err != nil {
+				return err
+			}
 
-	for _, file := range w.files {
-		stat, err := os.Stat(file)
-		if err != nil {
+			header.CRC32 = f.crc32
+			header.UncompressedSize64 = uint64(f.size)
+			header.CompressedSize64 = uint64(len(f.data))
+
+			dest, err := zipWriter.CreateRaw(header)
+			if err != nil {
+				return err
+			}
+
+			_, err = dest.Write(f.data)
+			return err
+		}); err != nil {
 			return err
 		}
 
-		if !stat.Mode().IsRegular() {
-			return fmt.Errorf("%s is not a regular file", stat.Name())
-		}
+		return zipWriter.Close()
+	})
+}
 
-		src, err := os.Open(file)
+func (w *Workshop) tarHeader(file string, stat os.FileInfo) (*tar.Header, error) {
+	header, err := tar.FileInfoHeader(stat, "")
+	if err != nil {
+		return nil, err
+	}
+
+	header.Name = filepath.ToSlash(file)
+
+	if w.reproducible {
+		header.ModTime = w.modTime
+		header.AccessTime = time.Time{}
+		header.ChangeTime = time.Time{}
+		header.Mode = 0644
+		header.Uid = 0
+		header.Gid = 0
+		header.Uname = ""
+		header.Gname = ""
+		header.Format = tar.FormatPAX
+	}
+
+	return header, nil
+}
+
+// TarFiles create a gzipped tarball of all files retrieved earlier using
+// GetFiles. Files are read in parallel but always written in the same order.
+// The tarball is written into a staging file first, which replaces the
+// previous one only when complete.
+func (w *Workshop) TarFiles() error {
+	if len(w.files) == 0 {
+		return errors.New("no files to archive")
+	}
+
+	return stageFile(w.absDestPath+FormatTarGz.Ext(), func(archive *os.File) error {
+		gzipWriter, err := gzip.NewWriterLevel(archive, w.compressionLevel)
 		if err != nil {
-			_ = src.Close()
 			return err
 		}
 
-		w, err := zipWriter.Create(file)
-		if err != nil {
-			_ = src.Close()
+		tarWriter := tar.NewWriter(gzipWriter)
+
+		if err := w.forEachFile(w.sortedFiles(), w.readFile, func(f *packedFile) error {
+			header, err := w.tarHeader(f.name, f.stat)
+			if err != nil {
+				return err
+			}
+
+			header.Size = f.size
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return err
+			}
+
+			_, err = tarWriter.Write(f.data)
+			return err
+		}); err != nil {
 			return err
 		}
 
-		if _, err := io.Copy(w, src); err != nil {
-			_ = src.Close()
+		if err := tarWriter.Close(); err != nil {
 			return err
 		}
 
-		_ = src.Close()
+		return gzipWriter.Close()
+	})
+}
+
+// DestExists checks if the destination exists based on the format: a non-empty
+// directory or an archive.
+func (w *Workshop) DestExists() bool {
+	if w.format.IsArchive() {
+		stat, _ := os.Stat(w.ArchivePath())
+		return stat != nil
 	}
+	total, _ := w.CountDestItems()
+	return total > 0
+}
 
-	_ = zipWriter.Close()
-	return nil
+// ArchivePath gets an absolute path of an archive created using either ZipFiles
+// or TarFiles based on the format. It's empty for a directory.
+func (w *Workshop) ArchivePath() string {
+	if !w.format.IsArchive() {
+		return ""
+	}
+	return w.absDestPath + w.format.Ext()
+}
+
+// VDFPath gets an absolute path of a VDF file describing a Steam Workshop item.
+func (w *Workshop) VDFPath() string {
+	return w.absDestPath + "_item.vdf"
+}
+
+// ArchiveChecksum calculates a SHA-256 checksum of an archive created using
+// either ZipFiles or TarFiles.
+func (w *Workshop) ArchiveChecksum() (string, error) {
+	f, err := os.Open(w.ArchivePath())
+	if err != nil {
+		return "", err
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
 // CountDestItems counts the total number of items within a destination
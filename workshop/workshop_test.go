@@ -0,0 +1,225 @@
+package workshop
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// chdir changes the working directory for the rest of the test as Dir lists
+// files relative to it.
+func chdir(t *testing.T, path string) {
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(path))
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+}
+
+// writeFiles writes files with the provided contents into the directory.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+}
+
+// touchFiles sets the modification time of all files in the directory.
+func touchFiles(t *testing.T, root string, modTime time.Time) {
+	assert.Nil(t, filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		return os.Chtimes(path, modTime, modTime)
+	}))
+}
+
+// newWorkshop creates a reproducible Workshop for the source directory with
+// files retrieved using GetFiles.
+func newWorkshop(t *testing.T, src string, format Format, jobs int) *Workshop {
+	chdir(t, src)
+
+	w, err := New(src, filepath.Join(t.TempDir(), "workshop"))
+	assert.Nil(t, err)
+
+	w.SetFormat(format)
+	w.SetJobs(jobs)
+	w.SetReproducible(true)
+
+	_, _, err = w.GetFiles()
+	assert.Nil(t, err)
+
+	return w
+}
+
+// build builds the package in the Workshop format and returns its path.
+func build(t *testing.T, w *Workshop) string {
+	switch w.Format() {
+	case FormatDir:
+		assert.Nil(t, w.CopyFiles())
+		return w.AbsDestPath()
+	case FormatZip:
+		assert.Nil(t, w.ZipFiles())
+	case FormatTarGz:
+		assert.Nil(t, w.TarFiles())
+	}
+	return w.ArchivePath()
+}
+
+func buildArchive(t *testing.T, src string, format Format, jobs int) string {
+	w := newWorkshop(t, src, format, jobs)
+	build(t, w)
+
+	checksum, err := w.ArchiveChecksum()
+	assert.Nil(t, err)
+
+	return checksum
+}
+
+func TestWorkshop_Reproducible(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"modinfo.lua":         `name = "Test"`,
+		"modmain.lua":         `print("modmain")`,
+		"scripts/foo.lua":     `return "foo"`,
+		"scripts/bar/baz.lua": `return "baz"`,
+		"images/icon.tex":     "tex",
+	})
+
+	for _, format := range []Format{FormatZip, FormatTarGz} {
+		touchFiles(t, src, time.Date(2010, time.January, 1, 0, 0, 0, 0, time.UTC))
+		expected := buildArchive(t, src, format, 1)
+
+		touchFiles(t, src, time.Date(2015, time.May, 5, 12, 30, 0, 0, time.UTC))
+		assert.Equalf(t, expected, buildArchive(t, src, format, 1), "%s with other modification times", format)
+		assert.Equalf(t, expected, buildArchive(t, src, format, 4), "%s with 4 jobs", format)
+	}
+}
+
+func TestWorkshop_ZipFiles_Names(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"modinfo.lua":         `name = "Test"`,
+		"scripts/привет.lua":  `return "привет"`,
+		"images/ícone.tex":    "tex",
+		"scripts/example.lua": `return "example"`,
+	})
+
+	r, err := zip.OpenReader(build(t, newWorkshop(t, src, FormatZip, 1)))
+	assert.Nil(t, err)
+	defer r.Close()
+
+	utf8Names := map[string]bool{
+		"images/ícone.tex":    true,
+		"modinfo.lua":         false,
+		"scripts/example.lua": false,
+		"scripts/привет.lua":  true,
+	}
+
+	if assert.Len(t, r.File, len(utf8Names)) {
+		for _, f := range r.File {
+			isUTF8, ok := utf8Names[f.Name]
+			assert.Truef(t, ok, "unexpected name %s", f.Name)
+			assert.Equalf(t, isUTF8, f.Flags&0x800 != 0, "%s UTF-8 flag", f.Name)
+			assert.Equalf(t, uint16(20), f.ReaderVersion, "%s reader version", f.Name)
+			assert.Equalf(t, uint16(20), f.CreatorVersion&0xff, "%s creator version", f.Name)
+		}
+	}
+}
+
+func TestModTimeFromEnv(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	modTime, err := ModTimeFromEnv()
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultModTime, modTime)
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1600000000")
+	modTime, err = ModTimeFromEnv()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, time.September, 13, 12, 26, 40, 0, time.UTC), modTime)
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	modTime, err = ModTimeFromEnv()
+	assert.EqualError(t, err, "invalid SOURCE_DATE_EPOCH value: yesterday")
+	assert.Equal(t, DefaultModTime, modTime)
+}
+
+// newReproducibleSrc creates a source directory where the walk order of files
+// differs from the sorted one.
+func newReproducibleSrc(t *testing.T) string {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"modinfo.lua":         `name = "Test"`,
+		"scripts/bar.lua":     `return "bar"`,
+		"scripts/bar/baz.lua": `return "baz"`,
+	})
+	assert.Nil(t, os.Chmod(filepath.Join(src, "scripts", "bar.lua"), 0o755))
+	touchFiles(t, src, time.Date(2015, time.May, 5, 12, 30, 0, 0, time.UTC))
+	return src
+}
+
+var reproducibleNames = []string{"modinfo.lua", "scripts/bar.lua", "scripts/bar/baz.lua"}
+
+func TestWorkshop_ZipFiles_Reproducible(t *testing.T) {
+	modTime := time.Date(2020, time.September, 13, 12, 26, 40, 0, time.UTC)
+
+	w := newWorkshop(t, newReproducibleSrc(t), FormatZip, 4)
+	w.SetModTime(modTime)
+
+	r, err := zip.OpenReader(build(t, w))
+	assert.Nil(t, err)
+	defer r.Close()
+
+	names := make([]string, len(r.File))
+	for i, f := range r.File {
+		names[i] = f.Name
+		assert.Equalf(t, modTime, f.Modified.UTC(), "%s modification time", f.Name)
+		assert.Equalf(t, os.FileMode(0o644), f.Mode(), "%s mode", f.Name)
+	}
+
+	assert.Equal(t, reproducibleNames, names)
+}
+
+func TestWorkshop_TarFiles_Reproducible(t *testing.T) {
+	modTime := time.Date(2020, time.September, 13, 12, 26, 40, 0, time.UTC)
+
+	w := newWorkshop(t, newReproducibleSrc(t), FormatTarGz, 4)
+	w.SetModTime(modTime)
+
+	f, err := os.Open(build(t, w))
+	assert.Nil(t, err)
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	assert.Nil(t, err)
+	assert.True(t, gz.ModTime.IsZero(), "gzip header shouldn't have a modification time")
+
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+
+		names = append(names, header.Name)
+		assert.Equalf(t, modTime, header.ModTime.UTC(), "%s modification time", header.Name)
+		assert.Equalf(t, int64(0o644), header.Mode, "%s mode", header.Name)
+		assert.Equalf(t, 0, header.Uid, "%s UID", header.Name)
+		assert.Equalf(t, 0, header.Gid, "%s GID", header.Name)
+		assert.Emptyf(t, header.Uname, "%s user name", header.Name)
+		assert.Emptyf(t, header.Gname, "%s group name", header.Name)
+	}
+
+	assert.Equal(t, reproducibleNames, names)
+}
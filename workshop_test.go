@@ -0,0 +1,333 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dstmodders/mod-cli/workshop"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFiles writes files with the provided contents into the directory.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+}
+
+// chdir changes the working directory for the rest of the test.
+func chdir(t *testing.T, path string) {
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(path))
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+}
+
+// fakeKtech puts a ktech script into PATH, which writes the PNG path into the
+// TEX file and an empty atlas.
+func fakeKtech(t *testing.T) {
+	bin := t.TempDir()
+	writeFiles(t, bin, map[string]string{
+		"ktech": `#!/bin/sh
+[ "$1" = "--version" ] && { echo "ktech 4.4.0"; exit 0; }
+echo "$3" > "$4" && echo "<Atlas/>" > "$2"
+`,
+	})
+	assert.Nil(t, os.Chmod(filepath.Join(bin, "ktech"), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// newAssetsMod creates a mod inside a temporary directory with PNG assets and
+// returns the directory along with the mod path.
+func newAssetsMod(t *testing.T) (root, mod string) {
+	root = t.TempDir()
+	mod = filepath.Join(root, "mod")
+	writeFiles(t, mod, map[string]string{
+		"modinfo.lua":                  `name = "Test"; version = "1.0.0"`,
+		"assets/src/images/icon.png":   "png",
+		"assets/src/images/readme.txt": "txt",
+	})
+	return root, mod
+}
+
+// newTestWorkshop creates a Workshop for the mod path with the default
+// destination name.
+func newTestWorkshop(cfg *Config, path string) *Workshop {
+	w := NewWorkshop(cfg)
+	w.destName = "workshop"
+	w.path = path
+	return w
+}
+
+func assetsConfig() *Config {
+	cfg := NewConfig()
+	cfg.Workshop.Assets.Src = "assets/src"
+	cfg.Workshop.Assets.Cache = ".assets"
+	return cfg
+}
+
+func TestWorkshop_compileAssets(t *testing.T) {
+	fakeKtech(t)
+	root, mod := newAssetsMod(t)
+
+	// assets are resolved against the mod path instead of the working directory
+	chdir(t, root)
+	w := newTestWorkshop(assetsConfig(), "mod")
+	assert.Nil(t, w.prepare())
+	assert.Nil(t, w.compileAssets())
+
+	for _, name := range []string{"images/icon.tex", "images/icon.xml"} {
+		assert.FileExists(t, filepath.Join(mod, ".assets", name))
+		assert.Truef(t, w.workshop.HasFile(name), "%s should be added", name)
+		assert.Equal(t, filepath.Join(mod, ".assets", name), w.workshop.SourcePath(filepath.FromSlash(name)))
+	}
+
+	_, err := os.Stat(filepath.Join(root, ".assets"))
+	assert.True(t, os.IsNotExist(err), "cache shouldn't be written into the working directory")
+
+	tex, err := os.ReadFile(filepath.Join(mod, ".assets", "images", "icon.tex"))
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.FromSlash("mod/assets/src/images/icon.png\n"), string(tex))
+}
+
+func TestWorkshop_run_List(t *testing.T) {
+	fakeKtech(t)
+	_, mod := newAssetsMod(t)
+
+	chdir(t, mod)
+	w := newTestWorkshop(assetsConfig(), ".")
+	w.list = true
+	assert.Nil(t, w.run())
+
+	assert.True(t, w.workshop.HasFile("modinfo.lua"))
+	assert.True(t, w.workshop.HasFile(filepath.FromSlash("images/icon.tex")))
+	assert.True(t, w.workshop.HasFile(filepath.FromSlash("images/icon.xml")))
+	assert.False(t, w.workshop.HasFile(filepath.FromSlash("assets/src/images/icon.png")))
+	assert.False(t, w.workshop.DestExists(), "listing shouldn't build anything")
+}
+
+func TestDirPattern(t *testing.T) {
+	root := filepath.FromSlash("/mods/mod")
+	tests := []struct {
+		dir      string
+		expected string
+		ok       bool
+	}{
+		{"assets/src", "/assets/src/", true},
+		{"./assets/src/", "/assets/src/", true},
+		{".assets", "/.assets/", true},
+		{filepath.Join(root, "assets", "src"), "/assets/src/", true},
+		{".", "", false},
+		{root, "", false},
+		{"../assets", "", false},
+		{filepath.FromSlash("/mods/assets"), "", false},
+	}
+
+	for _, tt := range tests {
+		pattern, ok := dirPattern(root, tt.dir)
+		assert.Equalf(t, tt.ok, ok, "Directory %q", tt.dir)
+		assert.Equalf(t, tt.expected, pattern, "Directory %q", tt.dir)
+	}
+}
+
+// newBuildMod creates a mod inside a temporary directory and changes the
+// working directory into it. It returns the temporary directory.
+func newBuildMod(t *testing.T) string {
+	root := t.TempDir()
+	mod := filepath.Join(root, "mod")
+	writeFiles(t, mod, map[string]string{
+		"modinfo.lua":      `name = "Test"; version = "1.0.0"`,
+		"modmain.lua":      "return",
+		"scripts/test.lua": "return",
+	})
+	chdir(t, mod)
+	return root
+}
+
+func buildConfig() *Config {
+	cfg := NewConfig()
+	cfg.Workshop.Validate = false
+	return cfg
+}
+
+func TestWorkshop_run_Out(t *testing.T) {
+	root := newBuildMod(t)
+
+	cfg := buildConfig()
+	cfg.Workshop.Out = "../out"
+
+	w := newTestWorkshop(cfg, ".")
+	captureStdout(t, func() {
+		assert.Nil(t, w.run())
+	})
+
+	dest := filepath.Join(root, "out", "workshop")
+	assert.Equal(t, dest, w.workshop.AbsDestPath())
+	for _, name := range []string{"modinfo.lua", "modmain.lua", "scripts/test.lua"} {
+		assert.FileExists(t, filepath.Join(dest, filepath.FromSlash(name)))
+	}
+
+	_, err := os.Stat(filepath.Join(root, "mod", "workshop"))
+	assert.True(t, os.IsNotExist(err), "nothing should be written into the mod directory")
+
+	// the destination is outside of the mod, so it's not ignored
+	assert.NotContains(t, w.ignoreList(w.workshop), "workshop")
+}
+
+func TestWorkshop_run_TarGz(t *testing.T) {
+	root := newBuildMod(t)
+
+	cfg := buildConfig()
+	cfg.Workshop.Format = "tar.gz"
+
+	w := newTestWorkshop(cfg, ".")
+	captureStdout(t, func() {
+		assert.Nil(t, w.run())
+	})
+
+	archive := filepath.Join(root, "mod", "workshop.tar.gz")
+	assert.Equal(t, archive, w.workshop.ArchivePath())
+
+	f, err := os.Open(archive)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	assert.Nil(t, err)
+
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		names = append(names, header.Name)
+	}
+
+	assert.Equal(t, []string{"modinfo.lua", "modmain.lua", "scripts/test.lua"}, names)
+
+	_, err = os.Stat(filepath.Join(root, "mod", "workshop"))
+	assert.True(t, os.IsNotExist(err), "archive shouldn't leave a directory")
+}
+
+func TestWorkshop_ignoreList(t *testing.T) {
+	newBuildMod(t)
+
+	cfg := buildConfig()
+	cfg.Workshop.Ignore = make([]string, 1, 10)
+	cfg.Workshop.Ignore[0] = "*.md"
+
+	w := newTestWorkshop(cfg, ".")
+	assert.Nil(t, w.prepare())
+
+	assert.Equal(t, []string{"*.md", "workshop", "workshop_item.vdf"}, w.ignoreList(w.workshop))
+	assert.Equal(t, []string{"*.md", "", ""}, cfg.Workshop.Ignore[:3],
+		"the configured list shouldn't share its backing array")
+}
+
+func TestWorkshop_confirmOverride(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		yes         bool
+		noOverwrite bool
+		expected    error
+	}{
+		{"yes", "y\n", false, false, nil},
+		{"default", "\n", false, false, nil},
+		{"no", "n\n", false, false, errWorkshopCanceled},
+		{"interrupted", "\x03", false, false, errWorkshopCanceled},
+		{"force", "", true, false, nil},
+		{"no overwrite", "", false, true, errWorkshopDestExists},
+		{"no overwrite and force", "", true, true, errWorkshopDestExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWorkshop(NewConfig())
+			w.stdin = io.NopCloser(strings.NewReader(tt.input))
+			w.yes = tt.yes
+			w.noOverwrite = tt.noOverwrite
+
+			var err error
+			captureStdout(t, func() {
+				err = w.confirmOverride("Override")
+			})
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestWorkshop_confirmOverride_NotTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	assert.Nil(t, err)
+	defer r.Close()
+	defer w.Close()
+
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() {
+		os.Stdin = stdin
+	}()
+
+	err = NewWorkshop(NewConfig()).confirmOverride("Override")
+	assert.True(t, errors.Is(err, errWorkshopDestExists))
+	assert.EqualError(t, err, "destination already exists and can't ask to override as stdin is not a terminal. Use --yes or --no-overwrite")
+}
+
+func TestWorkshopExitCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{errors.New("failed"), exitCodeError},
+		{errWorkshopValidation, exitCodeValidation},
+		{fmt.Errorf("%w. Fix the errors above", errWorkshopValidation), exitCodeValidation},
+		{errWorkshopSizeBudget, exitCodeValidation},
+		{errWorkshopDestExists, exitCodeDestExists},
+		{fmt.Errorf("%w and can't ask", errWorkshopDestExists), exitCodeDestExists},
+		{errWorkshopCanceled, exitCodeCanceled},
+		{errWorkshopVersion, exitCodeVersion},
+		{fmt.Errorf("ktech: %w", errWorkshopVersion), exitCodeVersion},
+	}
+
+	for _, tt := range tests {
+		assert.Equalf(t, tt.expected, workshopExitCode(tt.err), "Error %q", tt.err)
+	}
+}
+
+func TestWorkshop_prepare_Reproducible(t *testing.T) {
+	newBuildMod(t)
+
+	cfg := buildConfig()
+	cfg.Workshop.Reproducible = true
+
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	w := newTestWorkshop(cfg, ".")
+	assert.Nil(t, w.prepare())
+	assert.True(t, w.workshop.Reproducible())
+	assert.Equal(t, workshop.DefaultModTime, w.workshop.ModTime())
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1600000000")
+	w = newTestWorkshop(cfg, ".")
+	assert.Nil(t, w.prepare())
+	assert.Equal(t, time.Unix(1600000000, 0).UTC(), w.workshop.ModTime())
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	w = newTestWorkshop(cfg, ".")
+	assert.EqualError(t, w.prepare(), "invalid SOURCE_DATE_EPOCH value: yesterday")
+}